@@ -0,0 +1,389 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jiebago
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wangshizebin/jiebago/tokenizer"
+)
+
+// TokenSynonym is the type of the tokens added by the synonym filter
+const TokenSynonym = "SYNONYM"
+
+// AnalyzedToken is a token of the analysis with its position in the token stream,
+// the synonyms of a token and the tokens starting inside it, such as the sub-words
+// of the search mode, are at its position.
+type AnalyzedToken struct {
+	tokenizer.Token
+	Position int `json:"position"`
+}
+
+// CharFilter changes the text before it is cut, the returned map keeps the offsets
+// of the changed text in the text.
+type CharFilter interface {
+	Filter(s string) *tokenizer.TextMap
+}
+
+// CharFilterFunc adapts an ordinary function to a CharFilter.
+type CharFilterFunc func(s string) *tokenizer.TextMap
+
+func (f CharFilterFunc) Filter(s string) *tokenizer.TextMap {
+	return f(s)
+}
+
+// TokenFilter changes, drops or adds the tokens of the analysis
+type TokenFilter interface {
+	Filter(tokens []AnalyzedToken) []AnalyzedToken
+}
+
+// TokenFilterFunc adapts an ordinary function to a TokenFilter.
+type TokenFilterFunc func(tokens []AnalyzedToken) []AnalyzedToken
+
+func (f TokenFilterFunc) Filter(tokens []AnalyzedToken) []AnalyzedToken {
+	return f(tokens)
+}
+
+// Analyzer turns a text into a token stream, modelled on the analyzers of Lucene:
+// the char filters change the text, the text is cut with the options, then the
+// token filters change the tokens. The offsets of the tokens are in the original
+// text. The zero value cuts in the accurate mode. The filter of the instance set by
+// SetTokenFilter is not used, the words are dropped by Options.Filter or the token
+// filters only.
+type Analyzer struct {
+	CharFilters []CharFilter
+	Options     CutOptions
+	Filters     []TokenFilter
+}
+
+// NormalizeCharFilter normalizes the text with the options
+func NormalizeCharFilter(opts tokenizer.NormalizeOptions) CharFilter {
+	return CharFilterFunc(func(s string) *tokenizer.TextMap {
+		return tokenizer.Normalize(s, opts)
+	})
+}
+
+// MarkupCharFilter strips the markup of the format from the text
+func MarkupCharFilter(format tokenizer.MarkupFormat) CharFilter {
+	return CharFilterFunc(func(s string) *tokenizer.TextMap {
+		return tokenizer.ParseMarkup(s, format)
+	})
+}
+
+// T2SCharFilter converts traditional Chinese to simplified Chinese
+func T2SCharFilter() CharFilter {
+	return CharFilterFunc(func(s string) *tokenizer.TextMap {
+		return tokenizer.GetT2SConverter().ConvertMap(s)
+	})
+}
+
+// LowercaseFilter lowercases the tokens
+func LowercaseFilter() TokenFilter {
+	return TokenFilterFunc(func(tokens []AnalyzedToken) []AnalyzedToken {
+		for i := range tokens {
+			tokens[i].Text = strings.ToLower(tokens[i].Text)
+		}
+		return tokens
+	})
+}
+
+// StemFilter turns the lowercase English words into their Porter stems
+func StemFilter() TokenFilter {
+	return TokenFilterFunc(func(tokens []AnalyzedToken) []AnalyzedToken {
+		for i := range tokens {
+			tokens[i].Text = tokenizer.Stem(tokens[i].Text)
+		}
+		return tokens
+	})
+}
+
+// StopFilter drops the stop words, the positions of the other tokens are kept
+func StopFilter() TokenFilter {
+	return dropFilter(func(token AnalyzedToken) bool {
+		return tokenizer.GetTFIDF().ExistStopWord(token.Text)
+	})
+}
+
+// PunctuationFilter drops the tokens of punctuation, symbols and whitespace
+func PunctuationFilter() TokenFilter {
+	return dropFilter(func(token AnalyzedToken) bool {
+		return tokenizer.IsPunctuation(token.Text)
+	})
+}
+
+// LengthFilter drops the tokens shorter than min or longer than max runes, a max
+// of 0 means no limit
+func LengthFilter(min, max int) TokenFilter {
+	return dropFilter(func(token AnalyzedToken) bool {
+		n := utf8.RuneCountInString(token.Text)
+		return n < min || (max > 0 && n > max)
+	})
+}
+
+// POSFilter keeps the tokens whose parts of speech in the dictionary start with one
+// of keep, if keep is not empty, and drops the ones starting with one of drop. The
+// words not in the dictionary are x, so "n" matches nr and ns but not the unknown
+// words.
+func POSFilter(keep, drop []string) TokenFilter {
+	match := func(prop string, props []string) bool {
+		for _, v := range props {
+			if strings.HasPrefix(prop, v) {
+				return true
+			}
+		}
+		return false
+	}
+	return dropFilter(func(token AnalyzedToken) bool {
+		prop := tokenizer.GetDictionary().GetProp(token.Text)
+		if prop == "" {
+			prop = "x"
+		}
+		return (len(keep) > 0 && !match(prop, keep)) || match(prop, drop)
+	})
+}
+
+// SynonymFilter adds the synonyms of the tokens at their positions and offsets, the
+// tokens replaced by explicit mappings are dropped
+func SynonymFilter(m *tokenizer.SynonymMap) TokenFilter {
+	return TokenFilterFunc(func(tokens []AnalyzedToken) []AnalyzedToken {
+		expanded := make([]AnalyzedToken, 0, len(tokens))
+		for _, token := range tokens {
+			synonyms, ok := m.Lookup(token.Text)
+			if !ok {
+				expanded = append(expanded, token)
+				continue
+			}
+			for _, synonym := range synonyms {
+				if strings.EqualFold(synonym, token.Text) {
+					expanded = append(expanded, token)
+					continue
+				}
+				v := token
+				v.Text = synonym
+				v.Type = TokenSynonym
+				v.Value = ""
+				expanded = append(expanded, v)
+			}
+		}
+		return expanded
+	})
+}
+
+func dropFilter(drop func(token AnalyzedToken) bool) TokenFilter {
+	return TokenFilterFunc(func(tokens []AnalyzedToken) []AnalyzedToken {
+		kept := tokens[:0]
+		for _, token := range tokens {
+			if !drop(token) {
+				kept = append(kept, token)
+			}
+		}
+		return kept
+	})
+}
+
+// AnalyzeWith analyzes the text with the analyzer
+func (g *JieBaGo) AnalyzeWith(s string, a *Analyzer) []AnalyzedToken {
+	if a == nil {
+		a = &Analyzer{}
+	}
+
+	text := s
+	maps := make([]*tokenizer.TextMap, 0, len(a.CharFilters))
+	for _, f := range a.CharFilters {
+		m := f.Filter(text)
+		maps = append(maps, m)
+		text = m.Text
+	}
+
+	opts := a.Options
+	opts.RuneOffsets = false
+	if opts.Filter == nil {
+		opts.Filter = &tokenizer.TokenFilter{}
+	}
+	tokens := g.CutWith(text, opts)
+	for i := len(maps) - 1; i >= 0; i-- {
+		tokens = maps[i].MapTokens(tokens)
+	}
+	if a.Options.RuneOffsets {
+		tokens = tokenizer.RuneOffsets(s, tokens)
+	}
+
+	// a token starting inside the previous tokens, such as a sub-word of the search
+	// mode, is at the position of the previous token
+	analyzed := make([]AnalyzedToken, 0, len(tokens))
+	position, end := -1, 0
+	for _, token := range tokens {
+		if position < 0 || token.Start >= end {
+			position++
+		}
+		if token.End > end {
+			end = token.End
+		}
+		analyzed = append(analyzed, AnalyzedToken{Token: token, Position: position})
+	}
+	for _, f := range a.Filters {
+		analyzed = f.Filter(analyzed)
+	}
+	return analyzed
+}
+
+// Analyze analyzes the text with the named analyzer, the empty name is the default
+// analyzer which cuts in the accurate mode
+func (g *JieBaGo) Analyze(s string, name string) ([]AnalyzedToken, error) {
+	if name == "" {
+		return g.AnalyzeWith(s, nil), nil
+	}
+	a := g.GetAnalyzer(name)
+	if a == nil {
+		return nil, errors.New("unknown analyzer: " + name)
+	}
+	return g.AnalyzeWith(s, a), nil
+}
+
+// RegisterAnalyzer registers a named analyzer, an analyzer of the same name is replaced
+func (g *JieBaGo) RegisterAnalyzer(name string, a *Analyzer) {
+	g.analyzersMu.Lock()
+	defer g.analyzersMu.Unlock()
+	if g.analyzers == nil {
+		g.analyzers = make(map[string]*Analyzer)
+	}
+	g.analyzers[name] = a
+}
+
+// GetAnalyzer returns the named analyzer, nil if there is none
+func (g *JieBaGo) GetAnalyzer(name string) *Analyzer {
+	g.analyzersMu.RLock()
+	defer g.analyzersMu.RUnlock()
+	return g.analyzers[name]
+}
+
+// AnalysisConfig is the config file of the named analyzers in JSON, such as
+//
+//	{"analyzers": {"search": {
+//	  "char_filters": [{"type": "normalize"}, {"type": "html"}],
+//	  "tokenizer": {"mode": "search"},
+//	  "filters": [{"type": "lowercase"}, {"type": "stop"},
+//	    {"type": "synonym", "synonyms": ["手机, 移动电话"]}, {"type": "length", "min": 2}]
+//	}}}
+type AnalysisConfig struct {
+	Analyzers map[string]AnalyzerConfig `json:"analyzers"`
+}
+
+// AnalyzerConfig is the config of an analyzer
+type AnalyzerConfig struct {
+	CharFilters []FilterConfig `json:"char_filters"`
+	Tokenizer   CutOptions     `json:"tokenizer"`
+	Filters     []FilterConfig `json:"filters"`
+}
+
+// FilterConfig is the config of a char filter or a token filter. The char filters
+// are normalize, html, markdown and t2s. The token filters are lowercase, stem, stop,
+// punctuation, length, pos and synonym.
+type FilterConfig struct {
+	Type      string                      `json:"type"`
+	Normalize *tokenizer.NormalizeOptions `json:"normalize,omitempty"` // options of normalize, the defaults if nil
+	Min       int                         `json:"min,omitempty"`       // minimal rune length of length
+	Max       int                         `json:"max,omitempty"`       // maximal rune length of length, 0 for no limit
+	Keep      []string                    `json:"keep,omitempty"`      // parts of speech kept by pos
+	Drop      []string                    `json:"drop,omitempty"`      // parts of speech dropped by pos
+	Synonyms  []string                    `json:"synonyms,omitempty"`  // rules of synonym, the synonyms dictionary if no rules and path
+	Path      string                      `json:"path,omitempty"`      // file of the rules of synonym, relative to the config file
+}
+
+// NewAnalyzer returns the analyzer of the config, the relative paths of the config
+// are in dir
+func NewAnalyzer(config AnalyzerConfig, dir string) (*Analyzer, error) {
+	a := &Analyzer{Options: config.Tokenizer}
+	for _, v := range config.CharFilters {
+		switch strings.ToLower(v.Type) {
+		case "normalize":
+			opts := tokenizer.DefaultNormalizeOptions
+			if v.Normalize != nil {
+				opts = *v.Normalize
+			}
+			a.CharFilters = append(a.CharFilters, NormalizeCharFilter(opts))
+		case "html":
+			a.CharFilters = append(a.CharFilters, MarkupCharFilter(tokenizer.MarkupHTML))
+		case "markdown":
+			a.CharFilters = append(a.CharFilters, MarkupCharFilter(tokenizer.MarkupMarkdown))
+		case "t2s":
+			a.CharFilters = append(a.CharFilters, T2SCharFilter())
+		default:
+			return nil, errors.New("unknown char filter: " + v.Type)
+		}
+	}
+
+	for _, v := range config.Filters {
+		switch strings.ToLower(v.Type) {
+		case "lowercase":
+			a.Filters = append(a.Filters, LowercaseFilter())
+		case "stem":
+			a.Filters = append(a.Filters, StemFilter())
+		case "stop":
+			a.Filters = append(a.Filters, StopFilter())
+		case "punctuation":
+			a.Filters = append(a.Filters, PunctuationFilter())
+		case "length":
+			a.Filters = append(a.Filters, LengthFilter(v.Min, v.Max))
+		case "pos":
+			a.Filters = append(a.Filters, POSFilter(v.Keep, v.Drop))
+		case "synonym":
+			if len(v.Synonyms) == 0 && v.Path == "" {
+				a.Filters = append(a.Filters, SynonymFilter(tokenizer.GetSynonyms()))
+				break
+			}
+			m := tokenizer.NewSynonymMap()
+			for _, rule := range v.Synonyms {
+				if err := m.AddRule(rule); err != nil {
+					return nil, err
+				}
+			}
+			if v.Path != "" {
+				path := v.Path
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				if err := m.Load(path); err != nil {
+					return nil, err
+				}
+			}
+			a.Filters = append(a.Filters, SynonymFilter(m))
+		default:
+			return nil, errors.New("unknown token filter: " + v.Type)
+		}
+	}
+	return a, nil
+}
+
+// LoadAnalyzers registers the named analyzers of the config file, none of them is
+// registered if one of them is invalid
+func (g *JieBaGo) LoadAnalyzers(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var config AnalysisConfig
+	if err = json.Unmarshal(data, &config); err != nil {
+		return errors.New("invalid analysis config " + filepath.Base(file) + ": " + err.Error())
+	}
+
+	analyzers := make(map[string]*Analyzer, len(config.Analyzers))
+	for name, v := range config.Analyzers {
+		a, err := NewAnalyzer(v, filepath.Dir(file))
+		if err != nil {
+			return errors.New("invalid analyzer " + name + ": " + err.Error())
+		}
+		analyzers[name] = a
+	}
+	for name, a := range analyzers {
+		g.RegisterAnalyzer(name, a)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -26,6 +27,9 @@ const (
 	ErrorWeightInteger
 	ErrorWeightRange
 	ErrorCountInteger
+	ErrorWordsMismatch
+	ErrorCutMode
+	ErrorBoolean
 )
 
 var (
@@ -39,104 +43,877 @@ func main() {
 	dictPath := flag.String("dict_path", "",
 		"dict_path specifies the path of dictionary, for example: -dict_path /data/dictionary")
 
+	maxWordLen := flag.Int("max_word_len", 0,
+		"max_word_len specifies the max word length of the fmm, bmm and bimm modes, 0 means the longest word in dictionary")
+
+	normalize := flag.Bool("normalize", false,
+		"normalize specifies whether to fold the full-width and compatibility characters and collapse whitespace before cutting")
+
+	lowerCase := flag.Bool("lower_case", false,
+		"lower_case specifies whether to fold the letters to lower case before cutting")
+
+	numericEntities := flag.Bool("numeric_entities", false,
+		"numeric_entities specifies whether to keep the numbers, money, dates, times and quantities as single words")
+
+	englishFilter := flag.Bool("english_filter", false,
+		"english_filter specifies whether to lowercase and stem the English words in the search mode and keyword extraction")
+
+	searchMaxGram := flag.Int("search_max_gram", 3,
+		"search_max_gram specifies the rune length of the longest dictionary sub-words in the search mode")
+
+	searchSingleChars := flag.Bool("search_single_chars", false,
+		"search_single_chars specifies whether to emit every character of the words in the search mode")
+
+	searchNoParent := flag.Bool("search_no_parent", false,
+		"search_no_parent specifies whether to drop the words which have sub-words in the search mode")
+
+	mixedRule := flag.String("mixed_rule", "keep",
+		"mixed_rule specifies how to cut the runs of Latin letters and digits such as iPhone14Pro: keep, split or both")
+
+	charClasses := flag.String("char_classes", "",
+		"char_classes specifies the extra classes of text characters separated by commas, for example: -char_classes Hiragana,Katakana,Hangul")
+
+	regExpText := flag.String("regexp_text", "",
+		"regexp_text specifies the regular expression of the text characters, the default is "+tokenizer.RegExpText)
+
+	regExpNumber := flag.String("regexp_number", "",
+		"regexp_number specifies the regular expression of the numbers, the default is "+tokenizer.RegExpNumber)
+
+	regExpEnglish := flag.String("regexp_english", "",
+		"regexp_english specifies the regular expression of the English characters, the default is "+tokenizer.RegExpEnglish)
+
+	regExpDelimiter := flag.String("regexp_delimiter", "",
+		"regexp_delimiter specifies the regular expression of the delimiters, the default is "+tokenizer.RegExpDelimiter)
+
+	analysisConfig := flag.String("analysis_config", "",
+		"analysis_config specifies the config file of the named analyzers, for example: -analysis_config /data/analysis.json")
+
 	flag.Parse()
 
 	jieBaGo = jiebago.NewJieBaGo(*dictPath)
+	jieBaGo.SetMaxWordLen(*maxWordLen)
+	jieBaGo.SetNumericEntities(*numericEntities)
+	jieBaGo.SetMixedRule(tokenizer.ParseMixedRule(*mixedRule))
+	jieBaGo.SetSearchOptions(&tokenizer.SearchOptions{
+		MaxGram:     *searchMaxGram,
+		SingleChars: *searchSingleChars,
+		NoParent:    *searchNoParent,
+	})
+	if *englishFilter {
+		jieBaGo.SetEnglishFilter(&tokenizer.DefaultEnglishFilter)
+	}
+	opts := tokenizer.CharClassOptions{
+		Text:      *regExpText,
+		Number:    *regExpNumber,
+		English:   *regExpEnglish,
+		Delimiter: *regExpDelimiter,
+	}
+	if *charClasses != "" {
+		opts.Extra = strings.Split(*charClasses, ",")
+	}
+	if err := jieBaGo.SetCharClassOptions(opts); err != nil {
+		log.Fatal(err)
+	}
+	if *normalize || *lowerCase {
+		opts := tokenizer.NormalizeOptions{LowerCase: *lowerCase}
+		if *normalize {
+			opts = tokenizer.DefaultNormalizeOptions
+			opts.LowerCase = *lowerCase
+		}
+		jieBaGo.SetNormalizeOptions(&opts)
+	}
+
+	if *analysisConfig != "" {
+		if err := jieBaGo.LoadAnalyzers(*analysisConfig); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	engine := gin.Default()
 
 	engine.Any("/cut_words", cutWordsHandler)
+	engine.Any("/cut_nbest", cutNBestHandler)
+	engine.Any("/cut_markup", cutMarkupHandler)
+	engine.Any("/cut_traditional", cutTraditionalHandler)
+	engine.Any("/convert", convertHandler)
+	engine.Any("/lattice", latticeHandler)
+	engine.Any("/debug/explain", explainHandler)
+	engine.Any("/score_segmentation", scoreSegmentationHandler)
+	engine.Any("/analyze", analyzeHandler)
+	engine.Any("/expand_synonyms", expandSynonymsHandler)
 	engine.Any("/extract_keywords", extractKeywordsHandler)
+	engine.Any("/extract_entities", extractEntitiesHandler)
+	engine.Any("/highlight", highlightHandler)
+	engine.Any("/detect_sensitive", detectSensitiveHandler)
 	engine.Any("/add_dict_word", addDictWordHandler)
 	engine.Any("/add_stop_word", addStopWordHandler)
+	engine.Any("/add_synonyms", addSynonymsHandler)
+
+	if err := engine.Run(*httpAddr); err != nil {
+		log.Print(err)
+	}
+}
+
+type RequestCutWord struct {
+	Sentence    string `json:"s"`
+	Mode        string `json:"mode"`
+	HMM         *bool  `json:"hmm"`
+	StopWords   bool   `json:"stop_words"`
+	Punctuation bool   `json:"punctuation"`
+	SingleChars bool   `json:"single_chars"`
+	Offsets     string `json:"offsets"`
+}
+
+type RequestCutMarkup struct {
+	Sentence string `json:"s"`
+	Markup   string `json:"markup"`
+	Mode     string `json:"mode"`
+}
+
+type RequestConvert struct {
+	Sentence string `json:"s"`
+	To       string `json:"to"`
+}
+
+type RequestCutNBest struct {
+	Sentence string `json:"s"`
+	Count    int    `json:"count"`
+}
+
+type RequestLattice struct {
+	Sentence string `json:"s"`
+	Format   string `json:"format"`
+}
+
+type RequestScoreSegmentation struct {
+	Sentence string   `json:"s"`
+	Words    []string `json:"words"`
+}
+
+type RequestAnalyze struct {
+	Sentence string `json:"s"`
+	Analyzer string `json:"analyzer"`
+}
+
+type RequestExpandSynonyms struct {
+	Sentence string `json:"s"`
+}
+
+type RequestExtractWord struct {
+	Sentence string `json:"s"`
+	Mode     string `json:"mode"`
+	Count    int    `json:"count"`
+	Markup   string `json:"markup"`
+}
+
+type RequestExtractEntity struct {
+	Sentence string `json:"s"`
+}
+
+type RequestHighlight struct {
+	Document     string `json:"s"`
+	Query        string `json:"q"`
+	PreTag       string `json:"pre_tag"`
+	PostTag      string `json:"post_tag"`
+	FragmentSize int    `json:"fragment_size"`
+	Fragments    int    `json:"fragments"`
+}
+
+type RequestDetectSensitive struct {
+	Sentence    string `json:"s"`
+	Mask        string `json:"mask"`
+	Replacement string `json:"replacement"`
+}
+
+type RequestAddWord struct {
+	Word   string `json:"s"`
+	Weight int    `json:"weight"`
+	Prop   string `json:"prop"`
+}
+
+type RequestAddSynonyms struct {
+	Rule string `json:"s"`
+}
+
+type Response struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func cutWordsHandler(c *gin.Context) {
+	sentence := ""
+	mode := ""
+	hmm := true
+	offsets := ""
+	var filter tokenizer.TokenFilter
+	if c.Request.Method == "GET" {
+		mode = strings.ToLower(c.DefaultQuery("mode", ""))
+		sentence = c.DefaultQuery("s", "")
+		params := []struct {
+			name  string
+			value *bool
+		}{
+			{"hmm", &hmm},
+			{"stop_words", &filter.StopWords},
+			{"punctuation", &filter.Punctuation},
+			{"single_chars", &filter.SingleChars},
+		}
+		for _, param := range params {
+			v, ok := c.GetQuery(param.name)
+			if !ok {
+				continue
+			}
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				c.JSON(http.StatusOK, struct {
+					Response
+					Words []string `json:"words"`
+				}{
+					Response: Response{
+						ErrCode: ErrorBoolean,
+						ErrMsg:  "the " + param.name + " must be a boolean",
+					},
+					Words: []string{},
+				})
+				return
+			}
+			*param.value = b
+		}
+		offsets = strings.ToLower(c.DefaultQuery("offsets", ""))
+	} else if c.Request.Method == "POST" {
+		var request RequestCutWord
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Words []string `json:"words"`
+			}{
+				Response: Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx","mode":"xx"}`),
+				},
+				Words: []string{},
+			})
+			return
+		}
+		mode = request.Mode
+		sentence = request.Sentence
+		if request.HMM != nil {
+			hmm = *request.HMM
+		}
+		filter.StopWords = request.StopWords
+		filter.Punctuation = request.Punctuation
+		filter.SingleChars = request.SingleChars
+		offsets = strings.ToLower(request.Offsets)
+	} else {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Words []string `json:"words"`
+		}{
+			Response: Response{
+				ErrCode: ErrorRequestMethod,
+				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+			},
+			Words: []string{},
+		})
+		return
+	}
+
+	var cutMode jiebago.CutMode
+	if err := cutMode.UnmarshalText([]byte(mode)); err != nil {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Words []string `json:"words"`
+		}{
+			Response: Response{
+				ErrCode: ErrorCutMode,
+				ErrMsg:  err.Error(),
+			},
+			Words: []string{},
+		})
+		return
+	}
+
+	opts := jiebago.CutOptions{
+		Mode:        cutMode,
+		NoHMM:       !hmm,
+		RuneOffsets: offsets == "rune",
+	}
+	if filter.Enabled() {
+		opts.Filter = &filter
+	}
+	tokens := jieBaGo.CutWith(sentence, opts)
+	words := tokenizer.TokenTexts(tokens)
+	if offsets != "byte" && offsets != "rune" {
+		tokens = nil
+	}
+
+	c.JSON(http.StatusOK, struct {
+		Response
+		Words  []string          `json:"words"`
+		Tokens []tokenizer.Token `json:"tokens,omitempty"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Words:  words,
+		Tokens: tokens,
+	})
+}
+
+func cutNBestHandler(c *gin.Context) {
+	sentence := ""
+	count := 0
+	if c.Request.Method == "GET" {
+		sentence = c.DefaultQuery("s", "")
+		w := c.DefaultQuery("count", "0")
+		var err error
+		count, err = strconv.Atoi(w)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Segmentations []tokenizer.Segmentation `json:"segmentations"`
+			}{
+				Response: Response{
+					ErrCode: ErrorCountInteger,
+					ErrMsg:  "the count must be an integer",
+				},
+				Segmentations: []tokenizer.Segmentation{},
+			})
+			return
+		}
+	} else if c.Request.Method == "POST" {
+		var request RequestCutNBest
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Segmentations []tokenizer.Segmentation `json:"segmentations"`
+			}{
+				Response: Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx","count":xx}`),
+				},
+				Segmentations: []tokenizer.Segmentation{},
+			})
+			return
+		}
+		sentence = request.Sentence
+		count = request.Count
+	} else {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Segmentations []tokenizer.Segmentation `json:"segmentations"`
+		}{
+			Response: Response{
+				ErrCode: ErrorRequestMethod,
+				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+			},
+			Segmentations: []tokenizer.Segmentation{},
+		})
+		return
+	}
+	if count <= 0 {
+		count = 5
+	}
+
+	c.JSON(http.StatusOK, struct {
+		Response
+		Segmentations []tokenizer.Segmentation `json:"segmentations"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Segmentations: jieBaGo.CutNBest(sentence, count),
+	})
+}
+
+func cutMarkupHandler(c *gin.Context) {
+	sentence := ""
+	markup := ""
+	mode := ""
+	if c.Request.Method == "GET" {
+		sentence = c.DefaultQuery("s", "")
+		markup = c.DefaultQuery("markup", "html")
+		mode = c.DefaultQuery("mode", "")
+	} else if c.Request.Method == "POST" {
+		var request RequestCutMarkup
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Tokens []tokenizer.Token `json:"tokens"`
+			}{
+				Response: Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx","markup":"xx","mode":"xx"}`),
+				},
+				Tokens: []tokenizer.Token{},
+			})
+			return
+		}
+		sentence = request.Sentence
+		markup = request.Markup
+		mode = request.Mode
+		if markup == "" {
+			markup = "html"
+		}
+	} else {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Tokens []tokenizer.Token `json:"tokens"`
+		}{
+			Response: Response{
+				ErrCode: ErrorRequestMethod,
+				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+			},
+			Tokens: []tokenizer.Token{},
+		})
+		return
+	}
+
+	var cutMode jiebago.CutMode
+	if err := cutMode.UnmarshalText([]byte(mode)); err != nil {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Tokens []tokenizer.Token `json:"tokens"`
+		}{
+			Response: Response{
+				ErrCode: ErrorCutMode,
+				ErrMsg:  err.Error(),
+			},
+			Tokens: []tokenizer.Token{},
+		})
+		return
+	}
+
+	tokens := jieBaGo.CutMarkup(sentence, tokenizer.ParseMarkupFormat(markup), cutMode)
+	c.JSON(http.StatusOK, struct {
+		Response
+		Tokens []tokenizer.Token `json:"tokens"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Tokens: tokens,
+	})
+}
+
+func cutTraditionalHandler(c *gin.Context) {
+	sentence := ""
+	mode := ""
+	if c.Request.Method == "GET" {
+		sentence = c.DefaultQuery("s", "")
+		mode = c.DefaultQuery("mode", "")
+	} else if c.Request.Method == "POST" {
+		var request RequestCutWord
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Tokens []tokenizer.Token `json:"tokens"`
+			}{
+				Response: Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx","mode":"xx"}`),
+				},
+				Tokens: []tokenizer.Token{},
+			})
+			return
+		}
+		sentence = request.Sentence
+		mode = request.Mode
+	} else {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Tokens []tokenizer.Token `json:"tokens"`
+		}{
+			Response: Response{
+				ErrCode: ErrorRequestMethod,
+				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+			},
+			Tokens: []tokenizer.Token{},
+		})
+		return
+	}
+
+	var cutMode jiebago.CutMode
+	if err := cutMode.UnmarshalText([]byte(mode)); err != nil {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Tokens []tokenizer.Token `json:"tokens"`
+		}{
+			Response: Response{
+				ErrCode: ErrorCutMode,
+				ErrMsg:  err.Error(),
+			},
+			Tokens: []tokenizer.Token{},
+		})
+		return
+	}
+
+	tokens := jieBaGo.CutTraditional(sentence, cutMode)
+	c.JSON(http.StatusOK, struct {
+		Response
+		Tokens []tokenizer.Token `json:"tokens"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Tokens: tokens,
+	})
+}
+
+func convertHandler(c *gin.Context) {
+	sentence := ""
+	to := ""
+	if c.Request.Method == "GET" {
+		sentence = c.DefaultQuery("s", "")
+		to = strings.ToLower(c.DefaultQuery("to", ""))
+	} else if c.Request.Method == "POST" {
+		var request RequestConvert
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Result string `json:"result"`
+			}{
+				Response: Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx","to":"xx"}`),
+				},
+			})
+			return
+		}
+		sentence = request.Sentence
+		to = strings.ToLower(request.To)
+	} else {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Result string `json:"result"`
+		}{
+			Response: Response{
+				ErrCode: ErrorRequestMethod,
+				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+			},
+		})
+		return
+	}
+
+	var result string
+	if to == "traditional" {
+		result = jieBaGo.ToTraditional(sentence)
+	} else {
+		result = jieBaGo.ToSimplified(sentence)
+	}
+
+	c.JSON(http.StatusOK, struct {
+		Response
+		Result string `json:"result"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Result: result,
+	})
+}
+
+func latticeHandler(c *gin.Context) {
+	sentence := ""
+	format := ""
+	if c.Request.Method == "GET" {
+		sentence = c.DefaultQuery("s", "")
+		format = strings.ToLower(c.DefaultQuery("format", ""))
+	} else if c.Request.Method == "POST" {
+		var request RequestLattice
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Lattice *tokenizer.Lattice `json:"lattice"`
+			}{
+				Response: Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx","format":"xx"}`),
+				},
+			})
+			return
+		}
+		sentence = request.Sentence
+		format = strings.ToLower(request.Format)
+	} else {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Lattice *tokenizer.Lattice `json:"lattice"`
+		}{
+			Response: Response{
+				ErrCode: ErrorRequestMethod,
+				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+			},
+		})
+		return
+	}
+
+	lattice := jieBaGo.BuildLattice(sentence)
+	if format == "dot" {
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(lattice.DOT()))
+		return
+	}
+
+	c.JSON(http.StatusOK, struct {
+		Response
+		Lattice *tokenizer.Lattice `json:"lattice"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Lattice: lattice,
+	})
+}
+
+func explainHandler(c *gin.Context) {
+	sentence := ""
+	mode := ""
+	if c.Request.Method == "GET" {
+		mode = strings.ToLower(c.DefaultQuery("mode", ""))
+		sentence = c.DefaultQuery("s", "")
+	} else if c.Request.Method == "POST" {
+		var request RequestCutWord
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Tokens []tokenizer.ExplainToken `json:"tokens"`
+			}{
+				Response: Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx","mode":"xx"}`),
+				},
+				Tokens: []tokenizer.ExplainToken{},
+			})
+			return
+		}
+		mode = strings.ToLower(request.Mode)
+		sentence = request.Sentence
+	} else {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Tokens []tokenizer.ExplainToken `json:"tokens"`
+		}{
+			Response: Response{
+				ErrCode: ErrorRequestMethod,
+				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+			},
+			Tokens: []tokenizer.ExplainToken{},
+		})
+		return
+	}
+
+	var cutMode jiebago.CutMode
+	if err := cutMode.UnmarshalText([]byte(mode)); err != nil {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Tokens []tokenizer.ExplainToken `json:"tokens"`
+		}{
+			Response: Response{
+				ErrCode: ErrorCutMode,
+				ErrMsg:  err.Error(),
+			},
+			Tokens: []tokenizer.ExplainToken{},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, struct {
+		Response
+		Tokens []tokenizer.ExplainToken `json:"tokens"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Tokens: jieBaGo.Explain(sentence, cutMode != jiebago.ModeNoHMM),
+	})
+}
+
+func scoreSegmentationHandler(c *gin.Context) {
+	sentence := ""
+	var words []string
+	if c.Request.Method == "GET" {
+		sentence = c.DefaultQuery("s", "")
+		// the words are repeated, such as words=xx&words=xx, or a json array,
+		// such as words=["xx","xx"], since a word may have any character
+		words = c.QueryArray("words")
+		if len(words) == 1 && strings.HasPrefix(words[0], "[") {
+			var array []string
+			if err := json.Unmarshal([]byte(words[0]), &array); err != nil {
+				c.JSON(http.StatusOK, Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is words=["xx","xx"]`),
+				})
+				return
+			}
+			words = array
+		}
+	} else if c.Request.Method == "POST" {
+		var request RequestScoreSegmentation
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, Response{
+				ErrCode: ErrorJsonData,
+				ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx","words":["xx","xx"]}`),
+			})
+			return
+		}
+		sentence = request.Sentence
+		words = request.Words
+	} else {
+		c.JSON(http.StatusOK, Response{
+			ErrCode: ErrorRequestMethod,
+			ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+		})
+		return
+	}
 
-	if err := engine.Run(*httpAddr); err != nil {
-		log.Print(err)
+	proposed, jieba, err := jieBaGo.ScoreSegmentation(sentence, words)
+	if err != nil {
+		c.JSON(http.StatusOK, Response{
+			ErrCode: ErrorWordsMismatch,
+			ErrMsg:  err.Error(),
+		})
+		return
 	}
-}
 
-type RequestCutWord struct {
-	Sentence string `json:"s"`
-	Mode     string `json:"mode"`
+	c.JSON(http.StatusOK, struct {
+		Response
+		Proposed *tokenizer.SegmentationScore `json:"proposed"`
+		JieBa    *tokenizer.SegmentationScore `json:"jieba"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Proposed: proposed,
+		JieBa:    jieba,
+	})
 }
 
-type RequestExtractWord struct {
-	Sentence string `json:"s"`
-	Mode     string `json:"mode"`
-	Count    int    `json:"count"`
-}
+func analyzeHandler(c *gin.Context) {
+	sentence := ""
+	analyzer := ""
+	if c.Request.Method == "GET" {
+		sentence = c.DefaultQuery("s", "")
+		analyzer = c.DefaultQuery("analyzer", "")
+	} else if c.Request.Method == "POST" {
+		var request RequestAnalyze
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Tokens []jiebago.AnalyzedToken `json:"tokens"`
+			}{
+				Response: Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx","analyzer":"xx"}`),
+				},
+				Tokens: []jiebago.AnalyzedToken{},
+			})
+			return
+		}
+		sentence = request.Sentence
+		analyzer = request.Analyzer
+	} else {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Tokens []jiebago.AnalyzedToken `json:"tokens"`
+		}{
+			Response: Response{
+				ErrCode: ErrorRequestMethod,
+				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+			},
+			Tokens: []jiebago.AnalyzedToken{},
+		})
+		return
+	}
 
-type RequestAddWord struct {
-	Word   string `json:"s"`
-	Weight int    `json:"weight"`
-	Prop   string `json:"prop"`
-}
+	tokens, err := jieBaGo.Analyze(sentence, analyzer)
+	if err != nil {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Tokens []jiebago.AnalyzedToken `json:"tokens"`
+		}{
+			Response: Response{
+				ErrCode: ErrorFail,
+				ErrMsg:  err.Error(),
+			},
+			Tokens: []jiebago.AnalyzedToken{},
+		})
+		return
+	}
 
-type Response struct {
-	ErrCode int    `json:"errcode"`
-	ErrMsg  string `json:"errmsg"`
+	c.JSON(http.StatusOK, struct {
+		Response
+		Tokens []jiebago.AnalyzedToken `json:"tokens"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Tokens: tokens,
+	})
 }
 
-func cutWordsHandler(c *gin.Context) {
+func expandSynonymsHandler(c *gin.Context) {
 	sentence := ""
-	mode := ""
 	if c.Request.Method == "GET" {
-		mode = strings.ToLower(c.DefaultQuery("mode", ""))
 		sentence = c.DefaultQuery("s", "")
 	} else if c.Request.Method == "POST" {
-		var request RequestCutWord
+		var request RequestExpandSynonyms
 		err := c.BindJSON(&request)
 		if err != nil {
 			c.JSON(http.StatusOK, struct {
 				Response
-				Words []string `json:"words"`
+				Tokens []jiebago.AnalyzedToken `json:"tokens"`
 			}{
 				Response: Response{
 					ErrCode: ErrorJsonData,
-					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx","mode":"xx"}`),
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx"}`),
 				},
-				Words: []string{},
+				Tokens: []jiebago.AnalyzedToken{},
 			})
 			return
 		}
-		mode = request.Mode
 		sentence = request.Sentence
 	} else {
 		c.JSON(http.StatusOK, struct {
 			Response
-			Words []string `json:"words"`
+			Tokens []jiebago.AnalyzedToken `json:"tokens"`
 		}{
 			Response: Response{
 				ErrCode: ErrorRequestMethod,
 				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
 			},
-			Words: []string{},
+			Tokens: []jiebago.AnalyzedToken{},
 		})
 		return
 	}
 
-	var words []string
-	if mode == "full" {
-		words = jieBaGo.CutFull(sentence)
-	} else if mode == "accurate" {
-		words = jieBaGo.CutAccurate(sentence)
-	} else if mode == "nohmm" {
-		words = jieBaGo.CutNoHMM(sentence)
-	} else if mode == "search" {
-		words = jieBaGo.CutForSearch(sentence)
-	} else {
-		words = jieBaGo.Cut(sentence)
-	}
-
+	tokens := jieBaGo.ExpandSynonyms(sentence)
 	c.JSON(http.StatusOK, struct {
 		Response
-		Words []string `json:"words"`
+		Tokens []jiebago.AnalyzedToken `json:"tokens"`
 	}{
 		Response: Response{
 			ErrCode: Success,
 			ErrMsg:  "success",
 		},
-		Words: words,
+		Tokens: tokens,
 	})
 }
 
@@ -144,9 +921,11 @@ func extractKeywordsHandler(c *gin.Context) {
 	sentence := ""
 	count := 0
 	mode := ""
+	markup := ""
 	if c.Request.Method == "GET" {
 		sentence = c.DefaultQuery("s", "")
 		mode = c.DefaultQuery("mode", "")
+		markup = c.DefaultQuery("markup", "")
 		w := c.DefaultQuery("count", "0")
 		var err error
 		count, err = strconv.Atoi(w)
@@ -182,6 +961,7 @@ func extractKeywordsHandler(c *gin.Context) {
 		sentence = request.Sentence
 		mode = request.Mode
 		count = request.Count
+		markup = request.Markup
 	} else {
 		c.JSON(http.StatusOK, struct {
 			Response
@@ -198,9 +978,10 @@ func extractKeywordsHandler(c *gin.Context) {
 	if count <= 0 {
 		count = 20
 	}
+	format := tokenizer.ParseMarkupFormat(markup)
 
 	if mode == "weight" {
-		tags := jieBaGo.ExtractKeywordsWeight(sentence, count)
+		tags := jieBaGo.ExtractMarkupKeywordsWeight(sentence, format, count)
 		c.JSON(http.StatusOK, struct {
 			Response
 			Tags []tokenizer.Keyword `json:"tags"`
@@ -212,7 +993,7 @@ func extractKeywordsHandler(c *gin.Context) {
 			Tags: tags,
 		})
 	} else {
-		tags := jieBaGo.ExtractKeywords(sentence, count)
+		tags := jieBaGo.ExtractMarkupKeywords(sentence, format, count)
 		c.JSON(http.StatusOK, struct {
 			Response
 			Tags []string `json:"tags"`
@@ -226,6 +1007,207 @@ func extractKeywordsHandler(c *gin.Context) {
 	}
 }
 
+func extractEntitiesHandler(c *gin.Context) {
+	sentence := ""
+	if c.Request.Method == "GET" {
+		sentence = c.DefaultQuery("s", "")
+	} else if c.Request.Method == "POST" {
+		var request RequestExtractEntity
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Entities []tokenizer.Token `json:"entities"`
+			}{
+				Response: Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx"}`),
+				},
+				Entities: []tokenizer.Token{},
+			})
+			return
+		}
+		sentence = request.Sentence
+	} else {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Entities []tokenizer.Token `json:"entities"`
+		}{
+			Response: Response{
+				ErrCode: ErrorRequestMethod,
+				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+			},
+			Entities: []tokenizer.Token{},
+		})
+		return
+	}
+
+	entities := jieBaGo.ExtractEntities(sentence)
+	c.JSON(http.StatusOK, struct {
+		Response
+		Entities []tokenizer.Token `json:"entities"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Entities: entities,
+	})
+}
+
+func highlightHandler(c *gin.Context) {
+	document := ""
+	query := ""
+	var opts jiebago.HighlightOptions
+	if c.Request.Method == "GET" {
+		document = c.DefaultQuery("s", "")
+		query = c.DefaultQuery("q", "")
+		opts.PreTag = c.DefaultQuery("pre_tag", "")
+		opts.PostTag = c.DefaultQuery("post_tag", "")
+		var err error
+		opts.FragmentSize, err = strconv.Atoi(c.DefaultQuery("fragment_size", "0"))
+		if err == nil {
+			opts.Fragments, err = strconv.Atoi(c.DefaultQuery("fragments", "0"))
+		}
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Fragments []jiebago.Fragment `json:"fragments"`
+			}{
+				Response: Response{
+					ErrCode: ErrorCountInteger,
+					ErrMsg:  "the fragment_size and fragments must be integers",
+				},
+				Fragments: []jiebago.Fragment{},
+			})
+			return
+		}
+	} else if c.Request.Method == "POST" {
+		var request RequestHighlight
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Fragments []jiebago.Fragment `json:"fragments"`
+			}{
+				Response: Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg: fmt.Sprintf(`invalid json data, the proper data format is ` +
+						`{"s":"xx","q":"xx","pre_tag":"<em>","post_tag":"</em>","fragment_size":xx,"fragments":xx}`),
+				},
+				Fragments: []jiebago.Fragment{},
+			})
+			return
+		}
+		document = request.Document
+		query = request.Query
+		opts.PreTag = request.PreTag
+		opts.PostTag = request.PostTag
+		opts.FragmentSize = request.FragmentSize
+		opts.Fragments = request.Fragments
+	} else {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Fragments []jiebago.Fragment `json:"fragments"`
+		}{
+			Response: Response{
+				ErrCode: ErrorRequestMethod,
+				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+			},
+			Fragments: []jiebago.Fragment{},
+		})
+		return
+	}
+
+	fragments := jieBaGo.Highlight(document, query, &opts)
+	if fragments == nil {
+		fragments = []jiebago.Fragment{}
+	}
+	c.JSON(http.StatusOK, struct {
+		Response
+		Fragments []jiebago.Fragment `json:"fragments"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Fragments: fragments,
+	})
+}
+
+func detectSensitiveHandler(c *gin.Context) {
+	sentence := ""
+	mask := ""
+	replacement := ""
+	if c.Request.Method == "GET" {
+		sentence = c.DefaultQuery("s", "")
+		mask = c.DefaultQuery("mask", "")
+		replacement = c.DefaultQuery("replacement", "")
+	} else if c.Request.Method == "POST" {
+		var request RequestDetectSensitive
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, struct {
+				Response
+				Words []tokenizer.Token `json:"words"`
+				Text  string            `json:"text"`
+			}{
+				Response: Response{
+					ErrCode: ErrorJsonData,
+					ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx","mask":"*","replacement":"xx"}`),
+				},
+				Words: []tokenizer.Token{},
+			})
+			return
+		}
+		sentence = request.Sentence
+		mask = request.Mask
+		replacement = request.Replacement
+	} else {
+		c.JSON(http.StatusOK, struct {
+			Response
+			Words []tokenizer.Token `json:"words"`
+			Text  string            `json:"text"`
+		}{
+			Response: Response{
+				ErrCode: ErrorRequestMethod,
+				ErrMsg:  fmt.Sprintf(`iinvalid request method, only GET and POST methods are supported`),
+			},
+			Words: []tokenizer.Token{},
+		})
+		return
+	}
+
+	// the text has the words replaced by the replacement if there is one, or masked
+	words := jieBaGo.DetectSensitive(sentence)
+	text := ""
+	if replacement != "" {
+		text = tokenizer.ReplaceTokens(sentence, words, func(tokenizer.Token) string {
+			return replacement
+		})
+	} else {
+		if mask == "" {
+			mask = "*"
+		}
+		text = tokenizer.MaskTokens(sentence, words, mask)
+	}
+	if words == nil {
+		words = []tokenizer.Token{}
+	}
+	c.JSON(http.StatusOK, struct {
+		Response
+		Words []tokenizer.Token `json:"words"`
+		Text  string            `json:"text"`
+	}{
+		Response: Response{
+			ErrCode: Success,
+			ErrMsg:  "success",
+		},
+		Words: words,
+		Text:  text,
+	})
+}
+
 func addDictWordHandler(c *gin.Context) {
 	word := ""
 	weight := 0
@@ -342,3 +1324,48 @@ func addStopWordHandler(c *gin.Context) {
 		ErrMsg:  message,
 	})
 }
+
+func addSynonymsHandler(c *gin.Context) {
+	rule := ""
+	if c.Request.Method == "GET" {
+		rule = c.DefaultQuery("s", "")
+	} else if c.Request.Method == "POST" {
+		var request RequestAddSynonyms
+		err := c.BindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusOK, Response{
+				ErrCode: ErrorJsonData,
+				ErrMsg:  fmt.Sprintf(`invalid json data, the proper data format is {"s":"xx, xx"}`),
+			})
+			return
+		}
+		rule = request.Rule
+	}
+
+	rule = strings.TrimSpace(rule)
+	if len(rule) == 0 {
+		c.JSON(http.StatusOK, Response{
+			ErrCode: ErrorWordEmpty,
+			ErrMsg:  "the synonym rule is empty",
+		})
+		return
+	}
+
+	exist, err := jieBaGo.AddSynonyms(rule)
+	if err != nil {
+		c.JSON(http.StatusOK, Response{
+			ErrCode: ErrorFail,
+			ErrMsg:  err.Error(),
+		})
+		return
+	}
+
+	message := "success"
+	if exist {
+		message = "the synonyms already exist"
+	}
+	c.JSON(http.StatusOK, Response{
+		ErrCode: Success,
+		ErrMsg:  message,
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/wangshizebin/jiebago"
 	"github.com/wangshizebin/jiebago/tokenizer"
 )
 
@@ -29,7 +30,7 @@ func TestCutWordsGet(t *testing.T) {
 	t.Log(sentence)
 
 	url := "http://localhost:8118/cut_words?s=" + sentence
-	modes := []string{"", "accurate", "full", "nohmm", "search"}
+	modes := []string{"", "accurate", "full", "nohmm", "search", "fmm", "bmm", "bimm"}
 	for _, mode := range modes {
 		t.Log("=== mode: " + mode)
 		result, err := Get(url + "&mode=" + mode)
@@ -67,7 +68,7 @@ func TestCutWordsPost(t *testing.T) {
 	t.Log(sentence)
 
 	url := "http://localhost:8118/cut_words"
-	modes := []string{"", "accurate", "full", "nohmm", "search"}
+	modes := []string{"", "accurate", "full", "nohmm", "search", "fmm", "bmm", "bimm"}
 	for _, mode := range modes {
 		t.Log("=== mode: " + mode)
 		data := fmt.Sprintf(`{"s":"%s", "mode":"%s"}`, sentence, mode)
@@ -101,6 +102,420 @@ func TestCutWordsPost(t *testing.T) {
 	}
 }
 
+func TestCutWordsFilterGet(t *testing.T) {
+	t.Log(sentence)
+
+	url := "http://localhost:8118/cut_words?stop_words=true&punctuation=true&single_chars=true&s=" + sentence
+	result, err := Get(url)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var w struct {
+		Words []string `json:"words"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("结果：", strings.Join(w.Words, "/"))
+
+	for _, word := range w.Words {
+		if word == "，" || word == "与" || word == "。" {
+			t.Error(word + " not pass")
+		}
+	}
+	for _, word := range resultTest {
+		ok := false
+		for _, v := range w.Words {
+			if word == v {
+				ok = true
+			}
+		}
+		if !ok {
+			t.Error(word + " not pass")
+		}
+	}
+}
+
+func TestCutWordsOffsetsGet(t *testing.T) {
+	t.Log(sentence)
+
+	url := "http://localhost:8118/cut_words?mode=search&hmm=false&offsets=rune&s=" + sentence
+	result, err := Get(url)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var w struct {
+		Words  []string          `json:"words"`
+		Tokens []tokenizer.Token `json:"tokens"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("结果：", w.Tokens)
+
+	runes := []rune(sentence)
+	if len(w.Tokens) == 0 || len(w.Tokens) != len(w.Words) {
+		t.Error("offsets not pass")
+	}
+	for _, token := range w.Tokens {
+		if string(runes[token.Start:token.End]) != token.Text {
+			t.Error(token.Text + " not pass")
+		}
+	}
+}
+
+func TestCutUnknownModeGet(t *testing.T) {
+	paths := []string{"cut_words", "cut_markup", "cut_traditional", "debug/explain"}
+	for _, path := range paths {
+		result, err := Get("http://localhost:8118/" + path + "?mode=unknown&s=" + sentence)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var w struct {
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		}
+		err = json.Unmarshal([]byte(result), &w)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		t.Log("结果：", path, w.ErrCode, w.ErrMsg)
+		if w.ErrCode == 0 {
+			t.Error(path + " unknown mode not pass")
+		}
+	}
+}
+
+func TestCutWordsBooleanGet(t *testing.T) {
+	for _, param := range []string{"hmm=abc", "stop_words=abc", "punctuation=1", "single_chars=t"} {
+		result, err := Get("http://localhost:8118/cut_words?" + param + "&s=" + sentence)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var w struct {
+			ErrCode int      `json:"errcode"`
+			ErrMsg  string   `json:"errmsg"`
+			Words   []string `json:"words"`
+		}
+		err = json.Unmarshal([]byte(result), &w)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		t.Log("结果：", param, w.ErrCode, w.ErrMsg)
+		if strings.HasSuffix(param, "abc") != (w.ErrCode != 0) {
+			t.Error(param + " not pass")
+		}
+	}
+}
+
+func TestCutNBestGet(t *testing.T) {
+	t.Log(sentence)
+
+	url := "http://localhost:8118/cut_nbest?s=" + sentence + "&count=3"
+	result, err := Get(url)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var w struct {
+		Segmentations []tokenizer.Segmentation `json:"segmentations"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(w.Segmentations) == 0 || len(w.Segmentations) > 3 {
+		t.Error("count not pass")
+		return
+	}
+	for _, v := range w.Segmentations {
+		t.Log("结果：", strings.Join(v.Words, "/"), v.Score)
+	}
+
+	for _, word := range resultTest {
+		ok := false
+		for _, v := range w.Segmentations[0].Words {
+			if word == v {
+				ok = true
+			}
+		}
+		if !ok {
+			t.Error(word + " not pass")
+		} else {
+			t.Log(word + " OK")
+		}
+	}
+}
+
+func TestCutNBestPost(t *testing.T) {
+	t.Log(sentence)
+
+	url := "http://localhost:8118/cut_nbest"
+	data := fmt.Sprintf(`{"s":"%s", "count":%d}`, sentence, 3)
+	result, err := Post(url, data, "application/json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var w struct {
+		Segmentations []tokenizer.Segmentation `json:"segmentations"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(w.Segmentations) == 0 || len(w.Segmentations) > 3 {
+		t.Error("count not pass")
+		return
+	}
+	for i := 1; i < len(w.Segmentations); i++ {
+		if w.Segmentations[i].Score > w.Segmentations[i-1].Score {
+			t.Error("order not pass")
+		}
+	}
+	for _, v := range w.Segmentations {
+		t.Log("结果：", strings.Join(v.Words, "/"), v.Score)
+	}
+}
+
+func TestLatticeGet(t *testing.T) {
+	t.Log(sentence)
+
+	url := "http://localhost:8118/lattice?s=" + sentence
+	result, err := Get(url)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var w struct {
+		Lattice tokenizer.Lattice `json:"lattice"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, word := range []string{"操作系统", "用户"} {
+		ok := false
+		for _, v := range w.Lattice.Nodes {
+			if word == v.Word && v.Best {
+				ok = true
+			}
+		}
+		if !ok {
+			t.Error(word + " not pass")
+		} else {
+			t.Log(word + " OK")
+		}
+	}
+}
+
+func TestLatticePost(t *testing.T) {
+	t.Log(sentence)
+
+	url := "http://localhost:8118/lattice"
+	data := fmt.Sprintf(`{"s":"%s", "format":"%s"}`, sentence, "dot")
+	result, err := Post(url, data, "application/json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(result)
+	if !strings.HasPrefix(result, "digraph lattice {") {
+		t.Error("dot not pass")
+	}
+}
+
+func TestCutMarkupPost(t *testing.T) {
+	markup := "<p>" + sentence + "</p><script>var a = 1;</script>"
+	t.Log(markup)
+
+	url := "http://localhost:8118/cut_markup"
+	data, _ := json.Marshal(map[string]string{"s": markup, "markup": "html"})
+	result, err := Post(url, string(data), "application/json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var w struct {
+		Tokens []tokenizer.Token `json:"tokens"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("结果：", w.Tokens)
+
+	for _, token := range w.Tokens {
+		if markup[token.Start:token.End] != token.Text {
+			t.Error(token.Text + " not pass")
+		}
+	}
+	if len(w.Tokens) == 0 || strings.Contains(result, "var") {
+		t.Error("markup not pass")
+	}
+}
+
+func TestConvertGet(t *testing.T) {
+	url := "http://localhost:8118/convert?to=traditional&s=" + sentence
+	result, err := Get(url)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var w struct {
+		Result string `json:"result"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("结果：", w.Result)
+
+	result, err = Get("http://localhost:8118/convert?s=" + w.Result)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("结果：", w.Result)
+	if w.Result != sentence {
+		t.Error("convert not pass")
+	}
+}
+
+func TestExplainGet(t *testing.T) {
+	t.Log(sentence)
+
+	url := "http://localhost:8118/debug/explain?s=" + sentence
+	modes := []string{"", "nohmm"}
+	for _, mode := range modes {
+		t.Log("=== mode: " + mode)
+		result, err := Get(url + "&mode=" + mode)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var w struct {
+			Tokens []tokenizer.ExplainToken `json:"tokens"`
+		}
+		err = json.Unmarshal([]byte(result), &w)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		t.Log("结果：", w.Tokens)
+
+		for _, word := range resultTest {
+			ok := false
+			for _, v := range w.Tokens {
+				if word == v.Word && v.Source != "" {
+					ok = true
+				}
+			}
+			if !ok {
+				t.Error(word + " not pass")
+			} else {
+				t.Log(word + " OK")
+			}
+		}
+	}
+}
+
+func TestScoreSegmentationGet(t *testing.T) {
+	s := "用户/系统"
+	t.Log(s)
+
+	for _, words := range []string{
+		"words=用户&words=/&words=系统",
+		`words=["用户","/","系统"]`,
+	} {
+		url := "http://localhost:8118/score_segmentation?s=" + s + "&" + strings.ReplaceAll(words, `"`, "%22")
+		result, err := Get(url)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		var w struct {
+			ErrCode  int                          `json:"errcode"`
+			Proposed *tokenizer.SegmentationScore `json:"proposed"`
+			JieBa    *tokenizer.SegmentationScore `json:"jieba"`
+		}
+		err = json.Unmarshal([]byte(result), &w)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if w.ErrCode != 0 || w.Proposed == nil || w.JieBa == nil {
+			t.Error("score not pass")
+			return
+		}
+		t.Log("结果：", w.Proposed.Score, w.JieBa.Score)
+		if len(w.Proposed.Tokens) != 3 {
+			t.Error("tokens not pass")
+		}
+	}
+}
+
+func TestScoreSegmentationPost(t *testing.T) {
+	t.Log(sentence)
+
+	url := "http://localhost:8118/score_segmentation"
+	words := []string{"Shell", "位于", "用户", "与", "系统", "之间", "，", "用来", "帮助",
+		"用户", "与", "操作", "系统", "进行", "沟通", "。"}
+	data, _ := json.Marshal(struct {
+		Sentence string   `json:"s"`
+		Words    []string `json:"words"`
+	}{sentence, words})
+	result, err := Post(url, string(data), "application/json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var w struct {
+		ErrCode  int                          `json:"errcode"`
+		Proposed *tokenizer.SegmentationScore `json:"proposed"`
+		JieBa    *tokenizer.SegmentationScore `json:"jieba"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if w.ErrCode != 0 || w.Proposed == nil || w.JieBa == nil {
+		t.Error("score not pass")
+		return
+	}
+	t.Log("结果：", w.Proposed.Score, w.JieBa.Score)
+	if len(w.Proposed.Tokens) != len(words) {
+		t.Error("tokens not pass")
+	}
+}
+
 func TestExtractKeywordsGet(t *testing.T) {
 	t.Log(sentence)
 
@@ -247,6 +662,177 @@ func TestExtractKeywordsWeightPost(t *testing.T) {
 	}
 }
 
+func TestExtractEntitiesPost(t *testing.T) {
+	s := "张小凡毕业于清华大学，现在上海交通银行上班"
+	t.Log(s)
+
+	url := "http://localhost:8118/extract_entities"
+	data, _ := json.Marshal(map[string]string{"s": s})
+	result, err := Post(url, string(data), "application/json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var w struct {
+		Entities []tokenizer.Token `json:"entities"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("结果：", w.Entities)
+
+	expected := map[string]string{"张小凡": "PERSON", "清华大学": "ORGANIZATION"}
+	for _, entity := range w.Entities {
+		if s[entity.Start:entity.End] != entity.Text {
+			t.Error(entity.Text + " not pass")
+		}
+		if typ, ok := expected[entity.Text]; ok && typ == entity.Type {
+			delete(expected, entity.Text)
+		}
+	}
+	if len(expected) > 0 {
+		t.Error("extract entities not pass")
+	}
+}
+
+func TestAnalyzeGet(t *testing.T) {
+	t.Log(sentence)
+
+	result, err := Get("http://localhost:8118/analyze?s=" + sentence)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var w struct {
+		ErrCode int                     `json:"errcode"`
+		Tokens  []jiebago.AnalyzedToken `json:"tokens"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("结果：", w.Tokens)
+	if w.ErrCode != 0 || len(w.Tokens) == 0 {
+		t.Error("analyze not pass")
+	}
+	for i, token := range w.Tokens {
+		if token.Position != i || sentence[token.Start:token.End] != token.Text {
+			t.Error(token.Text + " not pass")
+		}
+	}
+
+	result, err = Get("http://localhost:8118/analyze?analyzer=unknown&s=" + sentence)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil || w.ErrCode == 0 {
+		t.Error("unknown analyzer not pass")
+	}
+}
+
+func TestExpandSynonymsGet(t *testing.T) {
+	s := "笔记本电脑"
+	t.Log(s)
+
+	result, err := Get("http://localhost:8118/expand_synonyms?s=" + s)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var w struct {
+		ErrCode int                     `json:"errcode"`
+		Tokens  []jiebago.AnalyzedToken `json:"tokens"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("结果：", w.Tokens)
+	if w.ErrCode != 0 {
+		t.Error("expand synonyms not pass")
+	}
+	found := false
+	for _, token := range w.Tokens {
+		if token.Text == "计算机" && token.Type == jiebago.TokenSynonym && s[token.Start:token.End] == "电脑" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("计算机 not pass")
+	}
+}
+
+func TestHighlightGet(t *testing.T) {
+	t.Log(sentence)
+
+	result, err := Get("http://localhost:8118/highlight?q=用户&fragments=1&s=" + sentence)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var w struct {
+		ErrCode   int                `json:"errcode"`
+		Fragments []jiebago.Fragment `json:"fragments"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("结果：", w.Fragments)
+	if w.ErrCode != 0 || len(w.Fragments) != 1 || !strings.Contains(w.Fragments[0].Text, "<em>用户</em>") {
+		t.Error("highlight not pass")
+	}
+
+	result, err = Get("http://localhost:8118/highlight?q=用户&fragments=x&s=" + sentence)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil || w.ErrCode == 0 {
+		t.Error("invalid fragments not pass")
+	}
+}
+
+func TestDetectSensitivePost(t *testing.T) {
+	url := "http://localhost:8118/detect_sensitive"
+	s := "他在网上赌 博，还参与網絡賭場"
+	t.Log(s)
+
+	data := fmt.Sprintf(`{"s":"%s", "replacement":"%s"}`, s, "[删除]")
+	result, err := Post(url, data, "application/json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var w struct {
+		ErrCode int               `json:"errcode"`
+		Words   []tokenizer.Token `json:"words"`
+		Text    string            `json:"text"`
+	}
+	err = json.Unmarshal([]byte(result), &w)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("结果：", w.Words, w.Text)
+	if w.ErrCode != 0 || len(w.Words) != 2 || w.Text != "他在网上[删除]，还参与[删除]" {
+		t.Error("detect sensitive not pass")
+	}
+	for _, word := range w.Words {
+		if s[word.Start:word.End] != word.Text || word.Type != "GAMBLING" {
+			t.Error(word.Text + " not pass")
+		}
+	}
+}
+
 func TestAddDictWordsGet(t *testing.T) {
 	word := "编程宝库"
 	t.Log("=== 添加字典单词: " + word)
@@ -392,3 +978,27 @@ func Post(url string, data string, contentType string) (string, error) {
 	}
 	return string(result), nil
 }
+
+func TestAddSynonymsGet(t *testing.T) {
+	rule := "电脑,计算机"
+	t.Log("=== 添加同义词: " + rule)
+	url := fmt.Sprintf(`http://localhost:8118/add_synonyms?s=%s`, rule)
+	result, err := Get(url)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var response struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	err = json.Unmarshal([]byte(result), &response)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if response.ErrCode != 0 {
+		t.Error(response.ErrMsg)
+	}
+	t.Log(response.ErrMsg)
+}
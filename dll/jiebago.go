@@ -7,6 +7,7 @@ package main
 import (
 	"C"
 	"encoding/json"
+	"strings"
 
 	"github.com/wangshizebin/jiebago"
 	"github.com/wangshizebin/jiebago/tokenizer"
@@ -21,6 +22,25 @@ func Init(path string) {
 	jieBaGo = jiebago.NewJieBaGo(path)
 }
 
+// InitWithOptions initializes with the options of the character classification in
+// JSON, such as {"text":"[a-z]+","number":"","english":"","delimiter":"","extra":["Hangul"]}
+//
+//export InitWithOptions
+func InitWithOptions(path string, options string) bool {
+	var opts tokenizer.CharClassOptions
+	if options != "" {
+		if err := json.Unmarshal([]byte(options), &opts); err != nil {
+			return false
+		}
+	}
+	g := jiebago.NewJieBaGo(path)
+	if err := g.SetCharClassOptions(opts); err != nil {
+		return false
+	}
+	jieBaGo = g
+	return true
+}
+
 //export Cut
 func Cut(sentence string) string {
 	if jieBaGo == nil {
@@ -35,7 +55,7 @@ func CutFull(sentence string) string {
 	if jieBaGo == nil {
 		return ""
 	}
-	words := jieBaGo.Cut(sentence)
+	words := tokenizer.TokenTexts(jieBaGo.CutWith(sentence, jiebago.CutOptions{Mode: jiebago.ModeFull}))
 	return wordsToJson(&words)
 }
 
@@ -44,7 +64,7 @@ func CutAccurate(sentence string) string {
 	if jieBaGo == nil {
 		return ""
 	}
-	words := jieBaGo.Cut(sentence)
+	words := tokenizer.TokenTexts(jieBaGo.CutWith(sentence, jiebago.CutOptions{Mode: jiebago.ModeAccurate}))
 	return wordsToJson(&words)
 }
 
@@ -53,7 +73,7 @@ func CutNoHMM(sentence string) string {
 	if jieBaGo == nil {
 		return ""
 	}
-	words := jieBaGo.Cut(sentence)
+	words := tokenizer.TokenTexts(jieBaGo.CutWith(sentence, jiebago.CutOptions{Mode: jiebago.ModeNoHMM}))
 	return wordsToJson(&words)
 }
 
@@ -62,10 +82,77 @@ func CutForSearch(sentence string) string {
 	if jieBaGo == nil {
 		return ""
 	}
-	words := jieBaGo.Cut(sentence)
+	words := tokenizer.TokenTexts(jieBaGo.CutWith(sentence, jiebago.CutOptions{Mode: jiebago.ModeSearch}))
+	return wordsToJson(&words)
+}
+
+//export CutFMM
+func CutFMM(sentence string) string {
+	if jieBaGo == nil {
+		return ""
+	}
+	words := jieBaGo.CutFMM(sentence)
+	return wordsToJson(&words)
+}
+
+//export CutBMM
+func CutBMM(sentence string) string {
+	if jieBaGo == nil {
+		return ""
+	}
+	words := jieBaGo.CutBMM(sentence)
+	return wordsToJson(&words)
+}
+
+//export CutBiMM
+func CutBiMM(sentence string) string {
+	if jieBaGo == nil {
+		return ""
+	}
+	words := jieBaGo.CutBiMM(sentence)
 	return wordsToJson(&words)
 }
 
+// CutWith cuts the sentence with the options in JSON, such as
+// {"mode":"search","no_hmm":false,"filter":{"stop_words":true},"rune_offsets":true}
+//
+//export CutWith
+func CutWith(sentence string, options string) string {
+	if jieBaGo == nil {
+		return ""
+	}
+	var opts jiebago.CutOptions
+	if options != "" {
+		if err := json.Unmarshal([]byte(options), &opts); err != nil {
+			return ""
+		}
+	}
+	tokens := jieBaGo.CutWith(sentence, opts)
+	return tokensToJson(&tokens)
+}
+
+//export SetMaxWordLen
+func SetMaxWordLen(n int) {
+	if jieBaGo == nil {
+		return
+	}
+	jieBaGo.SetMaxWordLen(n)
+}
+
+//export SetCharClasses
+func SetCharClasses(classes string) bool {
+	if jieBaGo == nil {
+		return false
+	}
+	var names []string
+	for _, v := range strings.Split(classes, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			names = append(names, v)
+		}
+	}
+	return jieBaGo.SetCharClasses(names...) == nil
+}
+
 //export ExtractKeywords
 func ExtractKeywords(s string, count int) string {
 	if jieBaGo == nil {
@@ -108,6 +195,42 @@ func AddStopWord(word string) bool {
 	return true
 }
 
+// DetectSensitive returns the sensitive words of the sentence in JSON, the type of a
+// token is its category
+//
+//export DetectSensitive
+func DetectSensitive(sentence string) string {
+	if jieBaGo == nil {
+		return ""
+	}
+	tokens := jieBaGo.DetectSensitive(sentence)
+	return tokensToJson(&tokens)
+}
+
+//export MaskSensitive
+func MaskSensitive(sentence string, mask string) string {
+	if jieBaGo == nil {
+		return ""
+	}
+	return jieBaGo.MaskSensitive(sentence, mask)
+}
+
+//export ReplaceSensitive
+func ReplaceSensitive(sentence string, replacement string) string {
+	if jieBaGo == nil {
+		return ""
+	}
+	return jieBaGo.ReplaceSensitive(sentence, replacement)
+}
+
+//export AddSensitiveWord
+func AddSensitiveWord(word string, category string) bool {
+	if jieBaGo == nil {
+		return false
+	}
+	return jieBaGo.AddSensitiveWord(word, category) == nil
+}
+
 func wordsToJson(words *[]string) string {
 	w := struct {
 		Words *[]string `json:"words"`
@@ -118,6 +241,16 @@ func wordsToJson(words *[]string) string {
 	return string(v)
 }
 
+func tokensToJson(tokens *[]tokenizer.Token) string {
+	w := struct {
+		Tokens *[]tokenizer.Token `json:"tokens"`
+	}{
+		Tokens: tokens,
+	}
+	v, _ := json.Marshal(w)
+	return string(v)
+}
+
 func wordsWeightToJson(tags *[]tokenizer.Keyword) string {
 	w := struct {
 		Tags *[]tokenizer.Keyword `json:"tags"`
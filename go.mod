@@ -2,4 +2,7 @@ module github.com/wangshizebin/jiebago
 
 go 1.16
 
-require github.com/gin-gonic/gin v1.7.7
+require (
+	github.com/gin-gonic/gin v1.7.7
+	golang.org/x/text v0.16.0
+)
@@ -0,0 +1,245 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jiebago
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/wangshizebin/jiebago/tokenizer"
+)
+
+// HighlightOptions are the options of Highlight, the zero fields are the defaults
+type HighlightOptions struct {
+	PreTag       string `json:"pre_tag"`       // tag before a match
+	PostTag      string `json:"post_tag"`      // tag after a match
+	FragmentSize int    `json:"fragment_size"` // maximal rune length of a fragment, unless a match is longer
+	Fragments    int    `json:"fragments"`     // maximal number of fragments
+}
+
+// DefaultHighlightOptions wraps the matches in <em> and returns the best 3 fragments
+// of up to 100 characters
+var DefaultHighlightOptions = HighlightOptions{
+	PreTag:       "<em>",
+	PostTag:      "</em>",
+	FragmentSize: 100,
+	Fragments:    3,
+}
+
+// Fragment is a highlighted fragment of a document
+type Fragment struct {
+	Text    string            `json:"text"`    // the fragment with the matches wrapped in the tags
+	Start   int               `json:"start"`   // offset of the fragment in the document
+	End     int               `json:"end"`     // end offset of the fragment in the document
+	Score   float64           `json:"score"`   // the distinct words matched plus the density of the matches
+	Matches []tokenizer.Token `json:"matches"` // the matches, with their offsets in the document
+}
+
+// Highlight returns the best fragments of the document for the query, both cut in
+// the search mode. A word of the document matches if it is a word of the query,
+// ignoring the case, so the matches are words of the document: in 研究生命, cut as
+// 研究/生命, the query 研究生 matches 研究 but not 研究生. The overlapping matches
+// are highlighted as one. The fragments start and end at word boundaries, and are
+// scored by the number of the distinct words they match, then by the density of
+// the matches, the share of their characters in the fragment. The fragments are in
+// the order of their scores, the best first. The stop words and punctuation of the
+// query are ignored. The document is not escaped, the tags are put in the text as
+// they are.
+func (g *JieBaGo) Highlight(document, query string, opts *HighlightOptions) []Fragment {
+	o := DefaultHighlightOptions
+	if opts != nil {
+		if opts.PreTag != "" || opts.PostTag != "" {
+			o.PreTag, o.PostTag = opts.PreTag, opts.PostTag
+		}
+		if opts.FragmentSize > 0 {
+			o.FragmentSize = opts.FragmentSize
+		}
+		if opts.Fragments > 0 {
+			o.Fragments = opts.Fragments
+		}
+	}
+
+	filter := tokenizer.TokenFilter{StopWords: true, Punctuation: true}
+	words := make(map[string]struct{})
+	for _, token := range g.CutWith(query, CutOptions{Mode: ModeSearch, Filter: &filter}) {
+		words[strings.ToLower(token.Text)] = struct{}{}
+	}
+	if len(words) == 0 {
+		return nil
+	}
+
+	// all the words of the document, the boundaries are between them
+	tokens := g.CutWith(document, CutOptions{Mode: ModeSearch, Filter: &tokenizer.TokenFilter{}})
+	var matches []tokenizer.Token
+	for _, token := range tokens {
+		if _, ok := words[strings.ToLower(token.Text)]; ok {
+			matches = append(matches, token)
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	matches = mergeMatches(document, matches)
+	boundaries := wordBoundaries(document, tokens)
+
+	var candidates []Fragment
+	for i := range matches {
+		candidates = append(candidates, highlightFragment(document, matches, i, boundaries, &o))
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	fragments := make([]Fragment, 0, o.Fragments)
+	for _, candidate := range candidates {
+		if len(fragments) == o.Fragments {
+			break
+		}
+		overlap := false
+		for _, v := range fragments {
+			if candidate.Start < v.End && v.Start < candidate.End {
+				overlap = true
+				break
+			}
+		}
+		if !overlap {
+			fragments = append(fragments, candidate)
+		}
+	}
+	return fragments
+}
+
+// Merge the overlapping matches, such as 笔记本 and 笔记本电脑 of the search mode,
+// the text of a merged match is the text of the document
+func mergeMatches(s string, matches []tokenizer.Token) []tokenizer.Token {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+	merged := make([]tokenizer.Token, 0, len(matches))
+	for _, match := range matches {
+		if n := len(merged); n > 0 && match.Start < merged[n-1].End {
+			if match.End > merged[n-1].End {
+				merged[n-1].End = match.End
+			}
+			continue
+		}
+		merged = append(merged, tokenizer.Token{Start: match.Start, End: match.End})
+	}
+	for i := range merged {
+		merged[i].Text = s[merged[i].Start:merged[i].End]
+	}
+	return merged
+}
+
+// The offsets of the text which are not inside a token, the short words of the
+// search mode inside the long ones do not make boundaries
+func wordBoundaries(s string, tokens []tokenizer.Token) []int {
+	offsets := []int{0, len(s)}
+	for _, token := range tokens {
+		offsets = append(offsets, token.Start, token.End)
+	}
+	sort.Ints(offsets)
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].Start < tokens[j].Start
+	})
+
+	boundaries := make([]int, 0, len(offsets))
+	k, end := 0, 0
+	for _, offset := range offsets {
+		if n := len(boundaries); n > 0 && boundaries[n-1] == offset {
+			continue
+		}
+		for ; k < len(tokens) && tokens[k].Start < offset; k++ {
+			if tokens[k].End > end {
+				end = tokens[k].End
+			}
+		}
+		if end <= offset {
+			boundaries = append(boundaries, offset)
+		}
+	}
+	return boundaries
+}
+
+// The fragment around the i-th match, it starts a quarter of the fragment size
+// before the match and ends at the last boundary within the fragment size
+func highlightFragment(s string, matches []tokenizer.Token, i int, boundaries []int, o *HighlightOptions) Fragment {
+	anchor := matches[i]
+
+	// the start is after the match before the anchor
+	lower := 0
+	if i > 0 {
+		lower = matches[i-1].End
+	}
+	start := anchor.Start
+	k := sort.SearchInts(boundaries, anchor.Start)
+	for ; k > 0; k-- {
+		b := boundaries[k-1]
+		if b < lower || utf8.RuneCountInString(s[b:anchor.Start]) > o.FragmentSize/4 {
+			break
+		}
+		start = b
+	}
+
+	// the end is before a match crossing it
+	end := anchor.End
+	for _, b := range boundaries[sort.SearchInts(boundaries, anchor.End):] {
+		if utf8.RuneCountInString(s[start:b]) > o.FragmentSize {
+			break
+		}
+		end = b
+	}
+	for _, match := range matches[i+1:] {
+		if match.Start >= end {
+			break
+		}
+		if match.End > end {
+			end = match.Start
+			break
+		}
+	}
+
+	// no whitespace at the ends, nor punctuation at the start
+	for start < anchor.Start {
+		r, size := utf8.DecodeRuneInString(s[start:])
+		if !unicode.IsSpace(r) && !unicode.IsPunct(r) {
+			break
+		}
+		start += size
+	}
+	for end > anchor.End {
+		r, size := utf8.DecodeLastRuneInString(s[:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		end -= size
+	}
+
+	fragment := Fragment{Start: start, End: end}
+	var b strings.Builder
+	last := start
+	matched := 0
+	distinct := make(map[string]struct{})
+	for _, match := range matches[i:] {
+		if match.Start >= end {
+			break
+		}
+		b.WriteString(s[last:match.Start])
+		b.WriteString(o.PreTag)
+		b.WriteString(match.Text)
+		b.WriteString(o.PostTag)
+		last = match.End
+
+		fragment.Matches = append(fragment.Matches, match)
+		matched += utf8.RuneCountInString(match.Text)
+		distinct[strings.ToLower(match.Text)] = struct{}{}
+	}
+	b.WriteString(s[last:end])
+	fragment.Text = b.String()
+	fragment.Score = float64(len(distinct)) + float64(matched)/float64(utf8.RuneCountInString(s[start:end]))
+	return fragment
+}
@@ -5,12 +5,19 @@
 package jiebago
 
 import (
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/wangshizebin/jiebago/tokenizer"
 )
 
 type JieBaGo struct {
+	segmenter   *tokenizer.Segmenter // created by NewJieBaGo, or by the first setter of a zero value
+	segmenterMu sync.Mutex
+
+	analyzers   map[string]*Analyzer // named analyzers
+	analyzersMu sync.RWMutex
 }
 
 func NewJieBaGo(path ...string) *JieBaGo {
@@ -19,7 +26,9 @@ func NewJieBaGo(path ...string) *JieBaGo {
 		configPath = path[0]
 	}
 	tokenizer.Init(configPath)
-	jieBaGo := &JieBaGo{}
+	jieBaGo := &JieBaGo{
+		segmenter: tokenizer.NewSegmenter(),
+	}
 	return jieBaGo
 }
 
@@ -28,111 +37,424 @@ func (g *JieBaGo) Cut(sentence string) []string {
 }
 
 func (g *JieBaGo) CutFull(s string) []string {
-	wordsRet := make([]string, 0, tokenizer.DefaultWordsLen)
+	return tokenizer.TokenTexts(g.CutWith(s, CutOptions{Mode: ModeFull}))
+}
 
-	segments := tokenizer.SplitTextSeg(s)
-	for _, segment := range segments {
-		if strings.Trim(segment, " ") == "" {
-			continue
-		}
-		if tokenizer.IsTextChars(segment) {
-			tokenizer.CutFullW(segment, &wordsRet)
-		} else {
-			tokenizer.CutSymbolW(segment, &wordsRet)
-		}
+func (g *JieBaGo) CutAccurate(s string) []string {
+	return tokenizer.TokenTexts(g.CutWith(s, CutOptions{Mode: ModeAccurate}))
+}
+
+func (g *JieBaGo) CutNoHMM(s string) []string {
+	return tokenizer.TokenTexts(g.CutWith(s, CutOptions{Mode: ModeNoHMM}))
+}
+
+func (g *JieBaGo) CutForSearch(s string) []string {
+	return tokenizer.TokenTexts(g.CutWith(s, CutOptions{Mode: ModeSearch}))
+}
+
+// CutFMM cuts the sentence by forward maximum matching
+func (g *JieBaGo) CutFMM(s string) []string {
+	return tokenizer.TokenTexts(g.CutWith(s, CutOptions{Mode: ModeFMM}))
+}
+
+// CutBMM cuts the sentence by backward maximum matching
+func (g *JieBaGo) CutBMM(s string) []string {
+	return tokenizer.TokenTexts(g.CutWith(s, CutOptions{Mode: ModeBMM}))
+}
+
+// CutBiMM cuts the sentence by bidirectional maximum matching
+func (g *JieBaGo) CutBiMM(s string) []string {
+	return tokenizer.TokenTexts(g.CutWith(s, CutOptions{Mode: ModeBiMM}))
+}
+
+// CutTokens cuts the sentence in the mode into tokens with byte offsets. The given
+// spans and the matches of the registered patterns are kept as single tokens
+// labelled with their types, only the text between them is cut.
+func (g *JieBaGo) CutTokens(s string, mode CutMode, spans ...tokenizer.Span) []tokenizer.Token {
+	return g.CutWith(s, CutOptions{Mode: mode, Spans: spans})
+}
+
+// CutWithFilter cuts the sentence in the mode and drops the words of the filter, the
+// filter of the instance set by SetTokenFilter is not used.
+func (g *JieBaGo) CutWithFilter(s string, mode CutMode, filter tokenizer.TokenFilter) []string {
+	return tokenizer.TokenTexts(g.CutWith(s, CutOptions{Mode: mode, Filter: &filter}))
+}
+
+// CutWith cuts the sentence into tokens with the options, all the Cut methods are
+// shortcuts of it. The words of the filter are dropped, and the English words of the
+// search mode are filtered by the English filter of the instance. The Text of a token
+// is the normalized and filtered word, s[Start:End] is the word in the sentence.
+func (g *JieBaGo) CutWith(s string, opts CutOptions) []tokenizer.Token {
+	filter := opts.Filter
+	if filter == nil {
+		filter = g.getSegmenter().GetTokenFilter()
+	}
+	tokens := filter.FilterTokens(g.cutTokens(s, &opts))
+	if opts.Mode == ModeSearch {
+		g.getSegmenter().FilterEnglishTokens(tokens)
+	}
+	if opts.RuneOffsets {
+		tokens = tokenizer.RuneOffsets(s, tokens)
 	}
-	return wordsRet
+	return tokens
 }
 
-func (g *JieBaGo) CutAccurate(s string) []string {
-	wordsRet := make([]string, 0, tokenizer.DefaultWordsLen)
+// SetSearchOptions sets the sub-words of the search mode: the longest dictionary
+// sub-words, the single characters and whether the long words themselves are
+// dropped. nil restores DefaultSearchOptions.
+func (g *JieBaGo) SetSearchOptions(opts *tokenizer.SearchOptions) {
+	g.instanceSegmenter().SetSearchOptions(opts)
+}
 
-	segments := tokenizer.SplitTextSeg(s)
-	for _, segment := range segments {
-		if strings.Trim(segment, " ") == "" {
-			continue
-		}
-		if tokenizer.IsTextChars(segment) {
-			tokenizer.CutAccurateW(segment, &wordsRet)
-		} else {
-			tokenizer.CutSymbolW(segment, &wordsRet)
-		}
+// SetTokenFilter sets the filter of the words of the Cut methods and CutTokens, such
+// as the stop words and punctuation. nil disables it.
+func (g *JieBaGo) SetTokenFilter(filter *tokenizer.TokenFilter) {
+	g.instanceSegmenter().SetTokenFilter(filter)
+}
+
+// RegisterPattern registers a named regular expression, its matches are kept as
+// single tokens labelled with the name in all the cut modes and keyword extraction.
+// A pattern of the same name is replaced.
+func (g *JieBaGo) RegisterPattern(name, expr string) error {
+	return g.instanceSegmenter().AddPattern(name, expr)
+}
+
+// RemovePattern removes the registered pattern of the name
+func (g *JieBaGo) RemovePattern(name string) {
+	g.getSegmenter().RemovePattern(name)
+}
+
+// SetCharClasses sets the extra classes of text characters of the instance, such as
+// Hiragana, Katakana or Hangul, their runs are kept as words. No classes restores
+// the default classification. All the Han characters are always Chinese characters.
+func (g *JieBaGo) SetCharClasses(classes ...string) error {
+	return g.SetCharClassOptions(tokenizer.CharClassOptions{Extra: classes})
+}
+
+// SetCharClassOptions sets the regular expressions of the character classification
+// of the instance, such as whether '+' or '#' are text characters. The classification
+// is left as it is if one of the regular expressions is invalid.
+func (g *JieBaGo) SetCharClassOptions(opts tokenizer.CharClassOptions) error {
+	class, err := tokenizer.NewCharClassWithOptions(opts)
+	if err != nil {
+		return err
 	}
+	g.instanceSegmenter().SetCharClass(class)
+	return nil
+}
 
-	return wordsRet
+// SetNumericEntities sets whether the numbers, money, dates, times and quantities,
+// such as 2022年3月5日 or 三千五百元, are kept as single typed tokens in all the cut
+// modes and keyword extraction. The tokens of CutTokens have the normalized values.
+func (g *JieBaGo) SetNumericEntities(enable bool) {
+	g.instanceSegmenter().SetNumericEntities(enable)
 }
 
-func (g *JieBaGo) CutNoHMM(s string) []string {
-	wordsRet := make([]string, 0, tokenizer.DefaultWordsLen)
+// ExtractNumericEntities returns the numbers, money, dates, times and quantities of
+// the sentence with their types and normalized values, such as 3500 CNY for 三千五百元.
+func (g *JieBaGo) ExtractNumericEntities(s string) []tokenizer.Token {
+	spans := tokenizer.FindNumericSpans(s)
+	tokens := make([]tokenizer.Token, 0, len(spans))
+	for _, span := range spans {
+		tokens = append(tokens, tokenizer.Token{
+			Text:  s[span.Start:span.End],
+			Start: span.Start,
+			End:   span.End,
+			Type:  span.Type,
+			Value: span.Value,
+		})
+	}
+	return tokens
+}
 
-	segments := tokenizer.SplitTextSeg(s)
-	for _, segment := range segments {
-		if strings.Trim(segment, " ") == "" {
-			continue
-		}
-		if tokenizer.IsTextChars(segment) {
-			tokenizer.CutNoHMMW(segment, &wordsRet)
-		} else {
-			tokenizer.CutSymbolW(segment, &wordsRet)
-		}
+// ExtractEntities returns the names of people, places and organizations of the
+// sentence, typed PERSON, LOCATION and ORGANIZATION, with their byte offsets. The
+// entities of the gazetteer are found first, then the words tagged nr, ns and nt in
+// the dictionary and the names recognized by the HMM are merged with their
+// neighbours, such as 北京 and 市 into 北京市. The entities of the other types of the
+// gazetteer, and the numeric entities if they are enabled, are returned as well.
+func (g *JieBaGo) ExtractEntities(s string) []tokenizer.Token {
+	spans := tokenizer.GetGazetteer().FindSpans(s)
+	entities := tokenizer.MergeEntities(g.cutTokens(s, &CutOptions{Mode: ModeAccurate, Spans: spans}))
+	for i := range entities {
+		entities[i].Text = s[entities[i].Start:entities[i].End]
 	}
+	return entities
+}
 
-	return wordsRet
+// AddEntity adds an entity to the gazetteer, the type is PERSON, LOCATION,
+// ORGANIZATION, a part of speech such as nr, or a type of the user
+func (g *JieBaGo) AddEntity(name, typ string) error {
+	return tokenizer.GetGazetteer().AddEntity(name, typ)
 }
 
-func (g *JieBaGo) CutForSearch(s string) []string {
-	wordsRet := make([]string, 0, tokenizer.DefaultWordsLen)
+// DetectSensitive returns the sensitive words in the text. The Type of a token is
+// the category, such as GAMBLING, and the Value is the word as listed. The words
+// written with full-width or traditional characters, or with separators such as
+// spaces between the characters, are detected as well.
+func (g *JieBaGo) DetectSensitive(s string) []tokenizer.Token {
+	return tokenizer.GetSensitiveWords().FindAll(s)
+}
+
+// MaskSensitive returns the text with every character of the sensitive words
+// replaced by the mask, * if the mask is empty
+func (g *JieBaGo) MaskSensitive(s string, mask string) string {
+	if mask == "" {
+		mask = "*"
+	}
+	return tokenizer.MaskTokens(s, g.DetectSensitive(s), mask)
+}
+
+// ReplaceSensitive returns the text with the sensitive words replaced by the
+// replacement
+func (g *JieBaGo) ReplaceSensitive(s string, replacement string) string {
+	return tokenizer.ReplaceTokens(s, g.DetectSensitive(s), func(tokenizer.Token) string {
+		return replacement
+	})
+}
+
+// AddSensitiveWord adds a sensitive word of the category, SENSITIVE if the category
+// is empty
+func (g *JieBaGo) AddSensitiveWord(word, category string) error {
+	return tokenizer.GetSensitiveWords().AddWord(word, category)
+}
+
+// SetMixedRule sets the rule of cutting the runs of Latin letters and digits, such as
+// iPhone14Pro, v1.2.3 or GPT-4, in all the cut modes: kept as single words, split at
+// case and digit boundaries, or both in the search mode. They are kept as single words
+// by default.
+func (g *JieBaGo) SetMixedRule(rule tokenizer.MixedRule) {
+	g.instanceSegmenter().SetMixedRule(rule)
+}
+
+// SetEnglishFilter enables the filter of the English words of CutForSearch and the
+// keyword extraction, such as DefaultEnglishFilter which lowercases and stems them, so
+// that Shell, shells and SHELL are the same word. nil disables it.
+func (g *JieBaGo) SetEnglishFilter(f *tokenizer.EnglishFilter) {
+	g.instanceSegmenter().SetEnglishFilter(f)
+}
 
-	segments := tokenizer.SplitTextSeg(s)
+// SetNormalizeOptions enables the normalization of the text before cutting in all
+// the cut modes and keyword extraction, nil disables it. The words are normalized,
+// and the offsets of the tokens are still in the original text.
+func (g *JieBaGo) SetNormalizeOptions(opts *tokenizer.NormalizeOptions) {
+	g.instanceSegmenter().SetNormalizeOptions(opts)
+}
+
+// Cut the sentence with the normalization and the protected spans of the options,
+// the words are not filtered
+func (g *JieBaGo) cutTokens(s string, opts *CutOptions) []tokenizer.Token {
+	tokens, m := g.cutUnmapped(s, opts)
+	if m != nil {
+		return m.MapTokens(tokens)
+	}
+	return tokens
+}
+
+// Cut the sentence the same way as cutTokens, the offsets of the tokens are the ones
+// of the normalized text, the map turns them into the ones of the sentence. The map
+// is nil if the sentence is not normalized.
+func (g *JieBaGo) cutUnmapped(s string, opts *CutOptions) ([]tokenizer.Token, *tokenizer.TextMap) {
+	var m *tokenizer.TextMap
+	if opts.Normalize != nil {
+		m = tokenizer.Normalize(s, *opts.Normalize)
+	} else {
+		m = g.getSegmenter().Normalize(s)
+	}
+	if m != nil {
+		return g.cutNormalized(m.Text, opts, m.MapSpans(opts.Spans)), m
+	}
+	return g.cutNormalized(s, opts, opts.Spans), nil
+}
+
+// Map a token of cutUnmapped to the sentence, false if it is dropped by the map
+func mapToken(m *tokenizer.TextMap, token tokenizer.Token) (tokenizer.Token, bool) {
+	if m == nil {
+		return token, true
+	}
+	mapped := m.MapTokens([]tokenizer.Token{token})
+	if len(mapped) == 0 {
+		return token, false
+	}
+	return mapped[0], true
+}
+
+func (g *JieBaGo) cutNormalized(s string, opts *CutOptions, spans []tokenizer.Span) []tokenizer.Token {
+	tokens := make([]tokenizer.Token, 0, tokenizer.DefaultWordsLen)
+
+	pos := 0
+	for _, span := range g.getSegmenter().ProtectedSpans(s, spans) {
+		g.cutSegments(s[pos:span.Start], pos, opts, &tokens)
+		tokens = append(tokens, tokenizer.Token{
+			Text:  s[span.Start:span.End],
+			Start: span.Start,
+			End:   span.End,
+			Type:  span.Type,
+			Value: span.Value,
+		})
+		pos = span.End
+	}
+	g.cutSegments(s[pos:], pos, opts, &tokens)
+	return tokens
+}
+
+// Split the text into text and symbol segments, the text segments are cut in the
+// mode, the symbol segments by CutSymbolT.
+func (g *JieBaGo) cutSegments(s string, base int, opts *CutOptions, tokens *[]tokenizer.Token) {
+	cutText := g.textCutter(opts)
+
+	class := g.getSegmenter().GetCharClass()
+	segments := class.SplitTextSeg(s)
 	for _, segment := range segments {
-		if strings.Trim(segment, " ") == "" {
-			continue
+		if strings.Trim(segment, " ") != "" {
+			if class.IsTextChars(segment) {
+				g.cutMixed(segment, base, opts.Mode, cutText, tokens)
+			} else {
+				g.getSegmenter().CutSymbolT(segment, base, tokens)
+			}
 		}
-		if tokenizer.IsTextChars(segment) {
-			g.cutForSearchW(segment, &wordsRet)
+		base += len(segment)
+	}
+}
+
+// Cut the mixed Latin and digit runs of the text by the mixed rule, the rest by cutText.
+// The runs of letters or digits only are words in all the cut modes, they are left to
+// cutText.
+func (g *JieBaGo) cutMixed(s string, base int, mode CutMode, cutText func(string, int, *[]tokenizer.Token),
+	tokens *[]tokenizer.Token) {
+	rule := g.getSegmenter().GetMixedRule()
+	for _, segment := range tokenizer.SplitMixedSeg(s) {
+		if tokenizer.IsMixedChars(segment) && !isPlainRun(segment) {
+			tokenizer.AppendMixedTokens(segment, base, rule, mode == ModeSearch, tokens)
 		} else {
-			tokenizer.CutSymbolW(segment, &wordsRet)
+			cutText(segment, base, tokens)
 		}
+		base += len(segment)
 	}
-
-	return wordsRet
 }
 
-func (g *JieBaGo) cutForSearchW(s string, words *[]string) {
-	dictionary := tokenizer.GetDictionary()
+// Whether the run is of ASCII letters only or of digits only
+func isPlainRun(s string) bool {
+	letters, digits := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+			letters++
+		case c >= '0' && c <= '9':
+			digits++
+		}
+	}
+	return letters == len(s) || digits == len(s)
+}
 
-	for _, word := range g.CutAccurate(s) {
-		wordRune := []rune(word)
-		if len(wordRune) > 2 {
-			for i := 0; i < len(wordRune)-1; i++ {
-				s := string(wordRune[i : i+2])
-				if dictionary.Exist(s) {
-					*words = append(*words, s)
-				}
-			}
+func (g *JieBaGo) textCutter(opts *CutOptions) func(string, int, *[]tokenizer.Token) {
+	if opts.cutText != nil {
+		return opts.cutText
+	}
+	segmenter := g.getSegmenter()
+	switch opts.Mode {
+	case ModeFull:
+		return segmenter.CutFullT
+	case ModeNoHMM:
+		return segmenter.CutNoHMMT
+	case ModeSearch:
+		cut := segmenter.CutAccurateT
+		if opts.NoHMM {
+			cut = segmenter.CutNoHMMT
 		}
-		if len(wordRune) > 3 {
-			for i := 0; i < len(wordRune)-2; i++ {
-				s := string(wordRune[i : i+3])
-				if dictionary.Exist(s) {
-					*words = append(*words, s)
-				}
-			}
+		search := segmenter.GetSearchOptions()
+		if opts.Search != nil {
+			search = *opts.Search
+		}
+		return searchCutter(cut, search)
+	case ModeFMM:
+		return segmenter.CutFMMT
+	case ModeBMM:
+		return segmenter.CutBMMT
+	case ModeBiMM:
+		return segmenter.CutBiMMT
+	default:
+		if opts.NoHMM {
+			return segmenter.CutNoHMMT
+		}
+		return segmenter.CutAccurateT
+	}
+}
+
+// Return the cutter of the search mode, which adds the sub-words of the words of cut
+func searchCutter(cut func(string, int, *[]tokenizer.Token), opts tokenizer.SearchOptions) func(string, int,
+	*[]tokenizer.Token) {
+	return func(s string, base int, tokens *[]tokenizer.Token) {
+		words := make([]tokenizer.Token, 0, tokenizer.DefaultWordsLen)
+		cut(s, base, &words)
+		for _, word := range words {
+			tokenizer.AppendSearchTokens(word, opts, tokens)
 		}
-		*words = append(*words, word)
 	}
 }
 
 func (g *JieBaGo) ExtractKeywords(s string, count int) []string {
-	keywords := tokenizer.GetTFIDF().ExtractKeywords(s, count, false)
+	keywords := tokenizer.GetTFIDF().ExtractKeywordsFromWords(g.cutKeywordWords(s), count, false)
 	return keywords.([]string)
 }
 
 func (g *JieBaGo) ExtractKeywordsWeight(s string, count int) []tokenizer.Keyword {
-	keywords := tokenizer.GetTFIDF().ExtractKeywords(s, count, true)
+	keywords := tokenizer.GetTFIDF().ExtractKeywordsFromWords(g.cutKeywordWords(s), count, true)
 	return []tokenizer.Keyword(keywords.(tokenizer.Keywords))
 }
 
+// CutMarkup cuts the visible text of the HTML or Markdown in the mode. Tags, comments
+// and code blocks are skipped and entities are decoded, the offsets of the tokens are
+// in the original markup. The words are filtered the same way as CutWith.
+func (g *JieBaGo) CutMarkup(s string, format tokenizer.MarkupFormat, mode CutMode) []tokenizer.Token {
+	m := tokenizer.ParseMarkup(s, format)
+	return m.MapTokens(g.CutWith(m.Text, CutOptions{Mode: mode}))
+}
+
+// ExtractMarkupKeywords extracts the keywords from the visible text of the HTML or Markdown
+func (g *JieBaGo) ExtractMarkupKeywords(s string, format tokenizer.MarkupFormat, count int) []string {
+	return g.ExtractKeywords(tokenizer.ParseMarkup(s, format).Text, count)
+}
+
+// ExtractMarkupKeywordsWeight extracts the keywords with their weights from the visible
+// text of the HTML or Markdown
+func (g *JieBaGo) ExtractMarkupKeywordsWeight(s string, format tokenizer.MarkupFormat, count int) []tokenizer.Keyword {
+	return g.ExtractKeywordsWeight(tokenizer.ParseMarkup(s, format).Text, count)
+}
+
+// ToSimplified converts the traditional Chinese text to simplified Chinese
+func (g *JieBaGo) ToSimplified(s string) string {
+	return tokenizer.GetT2SConverter().Convert(s)
+}
+
+// ToTraditional converts the simplified Chinese text to traditional Chinese
+func (g *JieBaGo) ToTraditional(s string) string {
+	return tokenizer.GetS2TConverter().Convert(s)
+}
+
+// CutTraditional cuts the traditional or mixed script text in the mode. The text is
+// converted to simplified Chinese and cut with the dictionary, the tokens are in the
+// original script with the offsets in the original text. The words are filtered the
+// same way as CutWith, the words changed by the normalization or the English filter
+// are kept as they are changed.
+func (g *JieBaGo) CutTraditional(s string, mode CutMode) []tokenizer.Token {
+	m := tokenizer.GetT2SConverter().ConvertMap(s)
+	tokens := g.CutWith(m.Text, CutOptions{Mode: mode})
+	for i, token := range tokens {
+		if token.Text == m.Text[token.Start:token.End] {
+			start, end := m.Offsets(token.Start, token.End)
+			tokens[i].Text = s[start:end]
+		}
+	}
+	return m.MapTokens(tokens)
+}
+
+// Cut the words for keyword extraction, the same way as TFIDF.ExtractKeywords but
+// with the settings of the instance
+func (g *JieBaGo) cutKeywordWords(s string) []string {
+	return g.getSegmenter().FilterEnglish(tokenizer.TokenTexts(g.cutTokens(s, &CutOptions{Mode: ModeAccurate})))
+}
+
 func (g *JieBaGo) AddDictWord(word string, freq int, prop string) (exist bool, err error) {
 	return tokenizer.GetDictionary().AddWord(word, freq, prop)
 }
@@ -140,3 +462,220 @@ func (g *JieBaGo) AddDictWord(word string, freq int, prop string) (exist bool, e
 func (g *JieBaGo) AddStopWord(word string) (exist bool, err error) {
 	return tokenizer.GetTFIDF().AddStopWord(word)
 }
+
+// AddSynonyms adds a rule of synonyms, such as "电脑, 计算机" or "微机 => 计算机", and
+// appends it to the user-defined synonyms file
+func (g *JieBaGo) AddSynonyms(rule string) (exist bool, err error) {
+	return tokenizer.GetSynonyms().AddUserRule(rule)
+}
+
+// GetSynonyms returns the words the word is expanded to by the synonyms dictionary
+func (g *JieBaGo) GetSynonyms(word string) []string {
+	synonyms, _ := tokenizer.GetSynonyms().Lookup(word)
+	return synonyms
+}
+
+// ExpandSynonyms cuts the sentence in the search mode and expands the tokens to
+// their synonyms by the synonyms dictionary, the synonyms of a token are at its
+// position and offsets, so that 电脑 of a query matches 计算机 of a document
+func (g *JieBaGo) ExpandSynonyms(s string) []AnalyzedToken {
+	return g.AnalyzeWith(s, &Analyzer{
+		Options: CutOptions{Mode: ModeSearch},
+		Filters: []TokenFilter{SynonymFilter(tokenizer.GetSynonyms())},
+	})
+}
+
+func (g *JieBaGo) DiscoverNewWords(texts []string, count int) []tokenizer.WordCandidate {
+	finder := tokenizer.NewWordFinder()
+	for _, s := range texts {
+		finder.Add(s)
+	}
+	return finder.Find(count)
+}
+
+// SetRecognizer replaces the built-in HMM used to recognize words missing from the
+// dictionary, nil restores the HMM.
+func (g *JieBaGo) SetRecognizer(r tokenizer.Recognizer) {
+	g.instanceSegmenter().SetRecognizer(r)
+}
+
+// The segmenter of the instance, or the global one for a zero value JieBaGo{}
+// before a setter is called
+func (g *JieBaGo) getSegmenter() *tokenizer.Segmenter {
+	g.segmenterMu.Lock()
+	defer g.segmenterMu.Unlock()
+	if g.segmenter == nil {
+		return tokenizer.GetSegmenter()
+	}
+	return g.segmenter
+}
+
+// The segmenter of the instance. NewJieBaGo creates it, it is created here only for
+// a zero value JieBaGo{}, which uses the global segmenter until a setter is called.
+func (g *JieBaGo) instanceSegmenter() *tokenizer.Segmenter {
+	g.segmenterMu.Lock()
+	defer g.segmenterMu.Unlock()
+	if g.segmenter == nil {
+		g.segmenter = tokenizer.NewSegmenter()
+	}
+	return g.segmenter
+}
+
+// SetBigramModel enables the bigram model for route scoring of the instance, nil
+// restores the unigram model of the dictionary.
+func (g *JieBaGo) SetBigramModel(m *tokenizer.BigramModel) {
+	g.instanceSegmenter().SetBigramModel(m)
+}
+
+// LoadBigramModel loads a file of word-pair counts and enables it for the instance
+func (g *JieBaGo) LoadBigramModel(file string) error {
+	m, err := tokenizer.LoadBigramModel(file)
+	if err != nil {
+		return err
+	}
+	g.SetBigramModel(m)
+	return nil
+}
+
+// SetMaxWordLen sets the maximal rune length of the words in the maximum matching
+// modes, 0 uses the length of the longest dictionary word.
+func (g *JieBaGo) SetMaxWordLen(n int) {
+	g.instanceSegmenter().SetMaxWordLen(n)
+}
+
+// CutNBest returns at most k best segmentations of the sentence with their log
+// probabilities, best first. The sentence is cut the same way as CutNoHMM, with the
+// settings of the instance but not its filter, and the best segmentation has the
+// words of CutNoHMM. Only the text between the symbols, the protected spans and the
+// runs of the mixed rule has alternatives, scored by the bigram model of the
+// instance if there is one, otherwise by the unigram model. The rest is cut the same
+// way in all of them and adds nothing to the scores.
+func (g *JieBaGo) CutNBest(s string, k int) []tokenizer.Segmentation {
+	if k <= 0 {
+		return []tokenizer.Segmentation{}
+	}
+
+	// the text is a token of its own, the alternatives are by the index of the token
+	segmenter := g.getSegmenter()
+	alternatives := make(map[int]tokenizer.Segmentations)
+	opts := CutOptions{Mode: ModeNoHMM}
+	opts.cutText = func(text string, base int, tokens *[]tokenizer.Token) {
+		alternatives[len(*tokens)] = segmenter.NBest(text, k)
+		*tokens = append(*tokens, tokenizer.Token{Text: text, Start: base, End: base + len(text)})
+	}
+	tokens, m := g.cutUnmapped(s, &opts)
+
+	results := tokenizer.Segmentations{{Words: []string{}}}
+	for i, token := range tokens {
+		if _, ok := mapToken(m, token); !ok {
+			continue
+		}
+		alternative, ok := alternatives[i]
+		if !ok {
+			alternative = tokenizer.Segmentations{{Words: []string{token.Text}}}
+		} else if len(alternative) == 0 {
+			continue
+		}
+
+		combined := make(tokenizer.Segmentations, 0, len(results)*len(alternative))
+		for _, result := range results {
+			for _, v := range alternative {
+				words := make([]string, 0, len(result.Words)+len(v.Words))
+				words = append(words, result.Words...)
+				words = append(words, v.Words...)
+				combined = append(combined, tokenizer.Segmentation{
+					Words: words,
+					Score: result.Score + v.Score,
+				})
+			}
+		}
+		sort.Stable(combined)
+		if len(combined) > k {
+			combined = combined[:k]
+		}
+		results = combined
+	}
+	return results
+}
+
+// BuildLattice returns every word candidate of the sentence, with the route chosen
+// by the instance marked.
+func (g *JieBaGo) BuildLattice(s string) *tokenizer.Lattice {
+	return g.getSegmenter().BuildLattice(s)
+}
+
+// Explain cuts the sentence the same way as CutAccurate, or CutNoHMM if hmm is false,
+// with the settings of the instance, and annotates every word with where it comes
+// from and its scores. The words cover the sentence, the filter of the instance is
+// not used. The offsets of the words are in the sentence, the words themselves are
+// normalized the same way as the words of Cut.
+func (g *JieBaGo) Explain(s string, hmm bool) []tokenizer.ExplainToken {
+	// the explanations of the words cut by the segmenter are by the index of the tokens
+	segmenter := g.getSegmenter()
+	explained := make(map[int]tokenizer.ExplainToken)
+	opts := CutOptions{Mode: ModeAccurate, NoHMM: !hmm}
+	opts.cutText = func(text string, base int, tokens *[]tokenizer.Token) {
+		words := make([]tokenizer.ExplainToken, 0, tokenizer.DefaultWordsLen)
+		segmenter.ExplainT(text, base, hmm, &words)
+		for _, word := range words {
+			explained[len(*tokens)] = word
+			*tokens = append(*tokens, tokenizer.Token{Text: word.Word, Start: word.Start, End: word.End})
+		}
+	}
+	tokens, m := g.cutUnmapped(s, &opts)
+
+	class := segmenter.GetCharClass()
+	results := make([]tokenizer.ExplainToken, 0, len(tokens))
+	for i, token := range tokens {
+		token, ok := mapToken(m, token)
+		if !ok {
+			continue
+		}
+		result, ok := explained[i]
+		if !ok {
+			result = tokenizer.ExplainToken{Word: token.Text, Type: token.Type}
+			if token.Type != "" {
+				result.Source = tokenizer.SourceSpan
+			} else if class.IsTextChars(token.Text) {
+				result.Source = tokenizer.SourceMixed
+			} else {
+				result.Source = tokenizer.SourceSymbol
+			}
+		}
+		result.Start, result.End = token.Start, token.End
+		results = append(results, result)
+	}
+	return results
+}
+
+// ScoreSegmentation scores a proposed segmentation of the sentence under the unigram
+// model, and the segmentation of Cut for comparison. An error is returned if the
+// words do not align with the sentence.
+func (g *JieBaGo) ScoreSegmentation(s string, words []string) (proposed, jieba *tokenizer.SegmentationScore, err error) {
+	proposed, err = tokenizer.ScoreWords(s, words)
+	if err != nil {
+		return nil, nil, err
+	}
+	jieba, err = tokenizer.ScoreWords(s, g.sentenceWords(s))
+	if err != nil {
+		return nil, nil, err
+	}
+	return proposed, jieba, nil
+}
+
+// Cut the sentence in the accurate mode into the words as they are in the sentence,
+// which are not normalized nor dropped by the filter of the instance. The words
+// normalized from the same characters, such as ( 2 ) of ⑵, are the characters once.
+func (g *JieBaGo) sentenceWords(s string) []string {
+	tokens := g.CutWith(s, CutOptions{Mode: ModeAccurate, Filter: &tokenizer.TokenFilter{}})
+	words := make([]string, 0, len(tokens))
+	end := 0
+	for _, token := range tokens {
+		if token.Start < end {
+			continue
+		}
+		words = append(words, s[token.Start:token.End])
+		end = token.End
+	}
+	return words
+}
@@ -5,8 +5,16 @@
 package jiebago
 
 import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/wangshizebin/jiebago/tokenizer"
 )
 
 var (
@@ -35,6 +43,226 @@ func TestCutForSearch(t *testing.T) {
 	testCutWords(jieBaGo.CutForSearch, t)
 }
 
+func TestCutFMM(t *testing.T) {
+	testCutWords(jieBaGo.CutFMM, t)
+}
+
+func TestCutBMM(t *testing.T) {
+	testCutWords(jieBaGo.CutBMM, t)
+}
+
+func TestCutBiMM(t *testing.T) {
+	testCutWords(jieBaGo.CutBiMM, t)
+}
+
+func TestMaxMatch(t *testing.T) {
+	s := "研究生命起源"
+	t.Log("原始语句： " + s)
+
+	fmm := jieBaGo.CutFMM(s)
+	bmm := jieBaGo.CutBMM(s)
+	t.Log("正向最大匹配：", strings.Join(fmm, "/"))
+	t.Log("逆向最大匹配：", strings.Join(bmm, "/"))
+	if strings.Join(fmm, "/") != "研究生/命/起源" {
+		t.Error("fmm not pass")
+	}
+	if strings.Join(bmm, "/") != "研究/生命/起源" {
+		t.Error("bmm not pass")
+	}
+	if strings.Join(jieBaGo.CutBiMM(s), "/") != "研究/生命/起源" {
+		t.Error("bimm not pass")
+	}
+
+	jieBaGo.SetMaxWordLen(2)
+	defer jieBaGo.SetMaxWordLen(0)
+	words := jieBaGo.CutFMM(s)
+	t.Log("最大词长为2：", strings.Join(words, "/"))
+	if strings.Join(words, "/") != "研究/生命/起源" {
+		t.Error("max word length not pass")
+	}
+}
+
+func TestCutNBest(t *testing.T) {
+	t.Log("原始语句： " + sentence)
+
+	results := jieBaGo.CutNBest(sentence, 5)
+	if len(results) != 5 {
+		t.Error("count not pass")
+		return
+	}
+	for i, v := range results {
+		t.Log("分词结果：", strings.Join(v.Words, "/"), v.Score)
+		if i > 0 && v.Score > results[i-1].Score {
+			t.Error("order not pass")
+		}
+	}
+	if strings.Join(results[0].Words, "/") != strings.Join(jieBaGo.CutNoHMM(sentence), "/") {
+		t.Error("best segmentation not pass")
+	}
+
+	// the settings of the instance are used
+	s := "ＳＨＥＬＬ在2022年3月5日结婚的和尚未结婚的"
+	t.Log("原始语句：", s)
+	m := tokenizer.NewBigramModel()
+	m.Add("结婚", "的", 40)
+	m.Add("的", "和", 20)
+	m.Add("和", "尚未", 20)
+	g := NewJieBaGo()
+	g.SetNormalizeOptions(&tokenizer.DefaultNormalizeOptions)
+	g.SetNumericEntities(true)
+	g.SetBigramModel(m)
+	results = g.CutNBest(s, 3)
+	for _, v := range results {
+		t.Log("分词结果：", strings.Join(v.Words, "/"), v.Score)
+	}
+	if len(results) == 0 || strings.Join(results[0].Words, "/") != strings.Join(g.CutNoHMM(s), "/") ||
+		!containsWord(results[0].Words, "SHELL") || !containsWord(results[0].Words, "2022年3月5日") ||
+		!containsWord(results[0].Words, "尚未") {
+		t.Error("instance settings not pass")
+	}
+}
+
+func TestBuildLattice(t *testing.T) {
+	t.Log("原始语句： " + sentence)
+
+	lattice := jieBaGo.BuildLattice(sentence)
+	best := make([]string, 0)
+	for _, node := range lattice.Nodes {
+		if sentence[node.ByteStart:node.ByteEnd] != node.Word {
+			t.Error(node.Word + " offset not pass")
+		}
+		if node.Best {
+			best = append(best, node.Word)
+		}
+	}
+	t.Log("最佳路径：", strings.Join(best, "/"))
+	if strings.Join(best, "") != sentence {
+		t.Error("best route not pass")
+	}
+	for _, word := range []string{"用户", "操作系统", "沟通"} {
+		ok := false
+		for _, v := range best {
+			if word == v {
+				ok = true
+			}
+		}
+		if !ok {
+			t.Error(word + " not pass")
+		} else {
+			t.Log(word + " OK")
+		}
+	}
+	t.Log(lattice.DOT())
+}
+
+func TestExplain(t *testing.T) {
+	s := "Shell是他来到了网易杭研大厦。"
+	t.Log("原始语句： " + s)
+
+	for _, hmm := range []bool{true, false} {
+		tokens := jieBaGo.Explain(s, hmm)
+		words := make([]string, 0, len(tokens))
+		for _, v := range tokens {
+			t.Logf("%s %s %d %.2f %.2f %s", v.Word, v.Source, v.Freq, v.Score, v.RouteScore, v.States)
+			words = append(words, v.Word)
+		}
+
+		var expected []string
+		if hmm {
+			expected = jieBaGo.CutAccurate(s)
+		} else {
+			expected = jieBaGo.CutNoHMM(s)
+		}
+		if strings.Join(words, "/") != strings.Join(expected, "/") {
+			t.Error("explain words not pass")
+		}
+
+		sources := map[string]string{"来到": tokenizer.SourceDict, "。": tokenizer.SourceSymbol}
+		if !hmm {
+			sources["Shell"] = tokenizer.SourceEnglish
+		}
+		for _, v := range tokens {
+			if source, ok := sources[v.Word]; ok && source != v.Source {
+				t.Error(v.Word + " source not pass")
+			}
+			if v.Source == tokenizer.SourceHMM && len([]rune(v.Word)) != len(v.States) {
+				t.Error(v.Word + " states not pass")
+			}
+		}
+	}
+
+	// the words of Cut with the settings of the instance
+	s = "ＳＨＥＬＬ在2022年3月5日发布v1.2.3版本"
+	t.Log("原始语句： " + s)
+	g := NewJieBaGo()
+	g.SetNormalizeOptions(&tokenizer.DefaultNormalizeOptions)
+	g.SetNumericEntities(true)
+	g.SetMixedRule(tokenizer.MixedKeep)
+	tokens := g.Explain(s, true)
+	words := make([]string, 0, len(tokens))
+	for _, v := range tokens {
+		t.Logf("%s %s %d %d %s", v.Word, v.Source, v.Start, v.End, v.Type)
+		words = append(words, v.Word)
+	}
+	if strings.Join(words, "/") != strings.Join(g.CutAccurate(s), "/") {
+		t.Error("explain settings not pass")
+	}
+	sources := map[string]string{"2022年3月5日": tokenizer.SourceSpan, "v1.2.3": tokenizer.SourceMixed}
+	for _, v := range tokens {
+		if source, ok := sources[v.Word]; ok && source != v.Source {
+			t.Error(v.Word + " source not pass")
+		}
+		if v.Word == "SHELL" && s[v.Start:v.End] != "ＳＨＥＬＬ" {
+			t.Error(v.Word + " offsets not pass")
+		}
+	}
+}
+
+func TestScoreSegmentation(t *testing.T) {
+	t.Log("原始语句： " + sentence)
+
+	words := []string{"Shell", "位于", "用户", "与", "系统", "之间", "，", "用来", "帮助",
+		"用户", "与", "操作", "系统", "进行", "沟通", "。"}
+	proposed, jieba, err := jieBaGo.ScoreSegmentation(sentence, words)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("标注分词：", proposed.Score, proposed.Tokens)
+	t.Log("结巴分词：", jieba.Score, jieba.Tokens)
+	if proposed.Score > jieba.Score {
+		t.Error("score not pass")
+	}
+
+	total := float64(0)
+	for _, v := range proposed.Tokens {
+		total += v.Score
+	}
+	if math.Abs(total-proposed.Score) > 1e-9 {
+		t.Error("token scores not pass")
+	}
+
+	_, _, err = jieBaGo.ScoreSegmentation(sentence, []string{"Shell", "位", "用户"})
+	if err == nil {
+		t.Error("alignment not pass")
+	} else {
+		t.Log(err)
+	}
+
+	g := NewJieBaGo()
+	g.SetNormalizeOptions(&tokenizer.NormalizeOptions{FullWidth: true, Compatibility: true, LowerCase: true})
+	s := "ＡＢＣ１２３是⑵号产品"
+	_, jieba, err = g.ScoreSegmentation(s, []string{"ＡＢＣ１２３", "是", "⑵", "号", "产品"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("结巴分词：", jieba.Score, jieba.Tokens)
+	if jieba.Tokens[0].Word != "ＡＢＣ１２３" {
+		t.Error("normalized score not pass")
+	}
+}
+
 func TestExtractKeywords(t *testing.T) {
 	t.Log("原始语句： " + sentence)
 
@@ -109,6 +337,170 @@ func TestAddStopWord(t *testing.T) {
 	}
 }
 
+func TestDiscoverNewWords(t *testing.T) {
+	texts := []string{
+		"今天我们去喝茶颜悦色，排队的人很多。",
+		"听说茶颜悦色在上海也开了店。",
+		"朋友送了我一杯茶颜悦色作为礼物。",
+		"长沙的茶颜悦色门口总是挤满游客！",
+		"他最喜欢茶颜悦色的奶茶",
+		"周末一起排队买茶颜悦色吧",
+		"茶颜悦色出了新品，味道不错。",
+	}
+	words := jieBaGo.DiscoverNewWords(texts, 10)
+	t.Log("新词发现：", words)
+	for _, v := range words {
+		if v.Word == "茶颜悦色" {
+			if v.SuggestFreq <= 0 {
+				t.Error("茶颜悦色 suggest freq not pass")
+			}
+			t.Log("茶颜悦色 OK")
+			return
+		}
+	}
+	t.Error("茶颜悦色 not pass")
+}
+
+func TestSetRecognizer(t *testing.T) {
+	s := "他来到了网易杭研大厦"
+	t.Log("原始语句： " + s)
+
+	recognized := make([]string, 0)
+	jieBaGo.SetRecognizer(tokenizer.RecognizerFunc(func(sentence string) []string {
+		recognized = append(recognized, sentence)
+		return []string{sentence}
+	}))
+	defer jieBaGo.SetRecognizer(nil)
+
+	words := jieBaGo.Cut(s)
+	t.Log("分词结果：", strings.Join(words, "/"))
+	if len(recognized) == 0 {
+		t.Error("recognizer not pass")
+	}
+	for _, word := range recognized {
+		ok := false
+		for _, v := range words {
+			if word == v {
+				ok = true
+			}
+		}
+		if !ok {
+			t.Error(word + " not pass")
+		} else {
+			t.Log(word + " OK")
+		}
+	}
+}
+
+func TestInvalidRecognizer(t *testing.T) {
+	s := "他来到了网易杭研大厦"
+	expected := strings.Join(jieBaGo.Cut(s), "/")
+
+	g := NewJieBaGo()
+	g.SetRecognizer(tokenizer.RecognizerFunc(func(sentence string) []string {
+		return []string{sentence + "x"}
+	}))
+	words := g.Cut(s)
+	t.Log("分词结果：", strings.Join(words, "/"))
+	if strings.Join(words, "/") != expected {
+		t.Error("recognizer words not pass")
+	}
+
+	g.SetRecognizer(stateRecognizer{})
+	words = g.Cut(s)
+	t.Log("分词结果：", strings.Join(words, "/"))
+	if strings.Join(words, "/") != expected {
+		t.Error("recognizer states not pass")
+	}
+}
+
+// a recognizer which loses the states of its words
+type stateRecognizer struct{}
+
+func (stateRecognizer) Cut(sentence string) []string {
+	words, _ := stateRecognizer{}.CutStates(sentence)
+	return words
+}
+
+func (stateRecognizer) CutStates(sentence string) ([]string, []string) {
+	words := make([]string, 0)
+	for _, r := range sentence {
+		words = append(words, string(r))
+	}
+	return words, words[:len(words)/2]
+}
+
+func TestHMMStates(t *testing.T) {
+	sentences := []string{"他说的确实在理", "韩冰在西湖边上散步吃饭", "南京市长江大桥上的游客"}
+	for _, s := range sentences {
+		t.Log("原始语句：", s)
+		words, states := tokenizer.GetFinalSeg().CutStates(s)
+		t.Log("分词结果：", strings.Join(words, "/"), states)
+		if strings.Join(words, "") != s {
+			t.Error(s + " not pass")
+		}
+		for i, state := range states {
+			n := len([]rune(words[i]))
+			if state != "S" && (len(state) != n || state[0] != 'B' || state[n-1] != 'E' ||
+				strings.Trim(state[1:n-1], "M") != "") {
+				t.Error(words[i] + " not pass")
+			}
+		}
+	}
+}
+
+func TestBigramModel(t *testing.T) {
+	// the word pairs are counted on the training sentences, and the model is evaluated
+	// on the sentences held out from them
+	training := [][]string{
+		{"请", "把", "手", "拿开"},
+		{"学生", "会", "写字"},
+		{"我", "和", "尚未", "结婚", "的", "朋友"},
+	}
+	gold := [][]string{
+		{"老师", "把", "手", "拿开", "了"},
+		{"他", "把", "手", "放下"},
+		{"他", "和", "尚未", "毕业", "的", "学生"},
+	}
+
+	var pairs strings.Builder
+	for _, words := range training {
+		prev := tokenizer.BigramBegin
+		for _, word := range words {
+			pairs.WriteString(prev + " " + word + " 20\n")
+			prev = word
+		}
+	}
+	file := filepath.Join(t.TempDir(), "bigram.txt")
+	if err := os.WriteFile(file, []byte(pairs.String()), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	cut := func() [][]string {
+		predicted := make([][]string, 0, len(gold))
+		for _, words := range gold {
+			predicted = append(predicted, jieBaGo.Cut(strings.Join(words, "")))
+		}
+		return predicted
+	}
+
+	predicted := cut()
+	unigram := tokenizer.Evaluate(gold, predicted)
+	t.Log("一元模型：", predicted, unigram)
+
+	if err := jieBaGo.LoadBigramModel(file); err != nil {
+		t.Fatal(err)
+	}
+	defer jieBaGo.SetBigramModel(nil)
+
+	predicted = cut()
+	bigram := tokenizer.Evaluate(gold, predicted)
+	t.Log("二元模型：", predicted, bigram)
+	if bigram.F1 <= unigram.F1 {
+		t.Error("bigram model not pass")
+	}
+}
+
 func testCutWords(f func(string) []string, t *testing.T) {
 	t.Log("原始语句： " + sentence)
 
@@ -128,3 +520,959 @@ func testCutWords(f func(string) []string, t *testing.T) {
 		}
 	}
 }
+
+func TestCutTokens(t *testing.T) {
+	s := "请访问https://example.com/a?b=1或发邮件给dev@example.com，编号SKU-2022-001"
+	t.Log("原始语句：", s)
+
+	if err := jieBaGo.RegisterPattern("url", `https?://[0-9A-Za-z./?=&_%-]+`); err != nil {
+		t.Fatal(err)
+	}
+	if err := jieBaGo.RegisterPattern("email", `[0-9A-Za-z._-]+@[0-9A-Za-z.-]+\.[A-Za-z]+`); err != nil {
+		t.Fatal(err)
+	}
+	defer jieBaGo.RemovePattern("url")
+	defer jieBaGo.RemovePattern("email")
+	if err := jieBaGo.RegisterPattern("", "x"); err == nil {
+		t.Error("empty pattern name not pass")
+	}
+
+	sku := strings.Index(s, "SKU")
+	spans := []tokenizer.Span{{Start: sku, End: len(s), Type: "sku"}}
+	expected := map[string]string{
+		"https://example.com/a?b=1": "url",
+		"dev@example.com":           "email",
+		"SKU-2022-001":              "sku",
+	}
+	for _, mode := range []CutMode{ModeAccurate, ModeFull, ModeNoHMM, ModeSearch, ModeFMM, ModeBMM, ModeBiMM} {
+		tokens := jieBaGo.CutTokens(s, mode, spans...)
+		t.Log(mode, "分词结果：", strings.Join(tokenizer.TokenTexts(tokens), "/"))
+
+		found := 0
+		for _, token := range tokens {
+			if s[token.Start:token.End] != token.Text {
+				t.Error(mode, token.Text+" offsets not pass")
+			}
+			if v, ok := expected[token.Text]; ok && v == token.Type {
+				found++
+			}
+		}
+		if found != len(expected) {
+			t.Error(mode, "protected spans not pass")
+		}
+	}
+
+	words := jieBaGo.CutAccurate(s)
+	if !containsWord(words, "dev@example.com") {
+		t.Error("registered pattern not pass")
+	}
+	keywords := jieBaGo.ExtractKeywords(s, 20)
+	t.Log("关键词：", keywords)
+	if !containsWord(keywords, "https://example.com/a?b=1") {
+		t.Error("keywords with pattern not pass")
+	}
+
+	if ParseCutMode("search") != ModeSearch || ParseCutMode("unknown") != ModeAccurate {
+		t.Error("cut mode not pass")
+	}
+	var mode CutMode
+	if mode.UnmarshalText([]byte("Search")) != nil || mode != ModeSearch {
+		t.Error("unmarshal cut mode not pass")
+	}
+	if mode.UnmarshalText([]byte("unknown")) == nil || mode != ModeSearch {
+		t.Error("unknown cut mode not pass")
+	}
+}
+
+func containsWord(words []string, word string) bool {
+	for _, v := range words {
+		if v == word {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCutMarkup(t *testing.T) {
+	html := `<html><head><title>标题</title></head><body><p class="a>b">我爱<b>北京</b>天安门&amp;故宫</p>` +
+		`<pre>不要分词</pre><!-- 注释 --><script>var s = "<p>";</script></body></html>`
+	t.Log("原始语句：", html)
+
+	tokens := jieBaGo.CutMarkup(html, tokenizer.MarkupHTML, ModeAccurate)
+	t.Log("分词结果：", tokens)
+	texts := tokenizer.TokenTexts(tokens)
+	for _, word := range []string{"北京", "天安门", "&", "故宫"} {
+		if !containsWord(texts, word) {
+			t.Error(word + " not pass")
+		}
+	}
+	for _, word := range []string{"标题", "不要", "注释", "var", "b"} {
+		if containsWord(texts, word) {
+			t.Error(word + " not pass")
+		}
+	}
+	for _, token := range tokens {
+		if token.Text == "&" {
+			if html[token.Start:token.End] != "&amp;" {
+				t.Error("entity offsets not pass")
+			}
+		} else if html[token.Start:token.End] != token.Text {
+			t.Error(token.Text + " offsets not pass")
+		}
+	}
+
+	markdown := "# 北京\n\n我爱**天安门**，见[故宫](https://example.com)。\n\n```go\nvar s = \"不要分词\"\n```\n" +
+		"- 用`code`写snake_case\n"
+	t.Log("原始语句：", markdown)
+	tokens = jieBaGo.CutMarkup(markdown, tokenizer.MarkupMarkdown, ModeAccurate)
+	t.Log("分词结果：", tokens)
+	texts = tokenizer.TokenTexts(tokens)
+	for _, word := range []string{"北京", "天安门", "故宫", "snake_case"} {
+		if !containsWord(texts, word) {
+			t.Error(word + " not pass")
+		}
+	}
+	for _, word := range []string{"**", "#", "https", "不要", "code", "-"} {
+		if containsWord(texts, word) {
+			t.Error(word + " not pass")
+		}
+	}
+	for _, token := range tokens {
+		if markdown[token.Start:token.End] != token.Text {
+			t.Error(token.Text + " offsets not pass")
+		}
+	}
+
+	markdown = "计算2 * 3和a*b\n*强调*文字\n\n    var s = \"不要分词\"\n\t缩进代码\n\n- 列表\n\n    列表的段落\n"
+	t.Log("原始语句：", markdown)
+	text := tokenizer.ParseMarkdown(markdown).Text
+	t.Log("可见文本：", text)
+	if !strings.Contains(text, "2 * 3和a*b") || strings.Contains(text, "*强调*") || !strings.Contains(text, "强调") {
+		t.Error("markdown emphasis not pass")
+	}
+	if strings.Contains(text, "不要") || strings.Contains(text, "缩进") || !strings.Contains(text, "列表的段落") {
+		t.Error("markdown indented code not pass")
+	}
+
+	keywords := jieBaGo.ExtractMarkupKeywords(html, tokenizer.MarkupHTML, 10)
+	t.Log("关键词：", keywords)
+	if containsWord(keywords, "var") || !containsWord(keywords, "天安门") {
+		t.Error("markup keywords not pass")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	s := "我爱北京天安门，ＡＢＣ１２３　ＧＯ语言　 ①号ﬁle"
+	t.Log("原始语句：", s)
+
+	g := NewJieBaGo()
+	tokens := g.CutTokens(s, ModeAccurate)
+	if containsWord(tokenizer.TokenTexts(tokens), "ABC123") {
+		t.Error("normalization not pass")
+	}
+
+	g.SetNormalizeOptions(&tokenizer.NormalizeOptions{
+		FullWidth:     true,
+		Compatibility: true,
+		CollapseSpace: true,
+		LowerCase:     true,
+	})
+	for _, mode := range []CutMode{ModeAccurate, ModeFull, ModeNoHMM, ModeSearch, ModeFMM, ModeBMM, ModeBiMM} {
+		tokens = g.CutTokens(s, mode)
+		t.Log(mode, "分词结果：", tokens)
+		texts := tokenizer.TokenTexts(tokens)
+		for _, word := range []string{"abc123", "go", "1", "file"} {
+			if !containsWord(texts, word) {
+				t.Error(mode, word+" not pass")
+			}
+		}
+		for _, token := range tokens {
+			if token.Text == "abc123" && s[token.Start:token.End] != "ＡＢＣ１２３" {
+				t.Error(mode, "offsets not pass")
+			}
+		}
+	}
+
+	keywords := g.ExtractKeywords("ＡＰＩ接口ＡＰＩ接口", 5)
+	t.Log("关键词：", keywords)
+	if !containsWord(keywords, "api") {
+		t.Error("normalized keywords not pass")
+	}
+
+	m := tokenizer.Normalize("Ⅻ⑵ﬃ１０℃", tokenizer.DefaultNormalizeOptions)
+	if m.Text != "XII(2)ffi10°C" {
+		t.Error("compatibility folding not pass: " + m.Text)
+	}
+
+	s = "⼀豈ｶﾀｶﾅ㈱½"
+	m = tokenizer.Normalize(s, tokenizer.DefaultNormalizeOptions)
+	t.Log("原始语句：", s, "归一结果：", m.Text)
+	if m.Text != "一豈カタカナ(株)1/2" {
+		t.Error("NFKC folding not pass: " + m.Text)
+	}
+	start := strings.Index(m.Text, "(株)")
+	if begin, end := m.Offsets(start, start+len("(株)")); s[begin:end] != "㈱" {
+		t.Error("NFKC offsets not pass")
+	}
+}
+
+func TestCutTraditional(t *testing.T) {
+	s := "我愛北京天安門，這裡的頭髮"
+	t.Log("原始语句：", s)
+
+	simplified := jieBaGo.ToSimplified(s)
+	t.Log("简体：", simplified)
+	if simplified != "我爱北京天安门，这里的头发" {
+		t.Error("to simplified not pass")
+	}
+	traditional := jieBaGo.ToTraditional(simplified)
+	t.Log("繁体：", traditional)
+	if traditional != s {
+		t.Error("to traditional not pass")
+	}
+
+	for _, mode := range []CutMode{ModeAccurate, ModeFull, ModeSearch, ModeBiMM} {
+		tokens := jieBaGo.CutTraditional(s, mode)
+		t.Log(mode, "分词结果：", tokens)
+		texts := tokenizer.TokenTexts(tokens)
+		for _, word := range []string{"北京", "天安門"} {
+			if !containsWord(texts, word) {
+				t.Error(mode, word+" not pass")
+			}
+		}
+		for _, token := range tokens {
+			if s[token.Start:token.End] != token.Text {
+				t.Error(mode, token.Text+" offsets not pass")
+			}
+		}
+	}
+}
+
+func TestCharClass(t *testing.T) {
+	s := "〇𠀀㐀是字，すごい！東京はとても大きい"
+	t.Log("原始语句：", s)
+
+	g := NewJieBaGo()
+	words := g.Cut(s)
+	t.Log("分词结果：", strings.Join(words, "/"))
+	if !containsWord(words, "〇") || containsWord(words, "〇𠀀㐀是字") {
+		t.Error("han characters not pass")
+	}
+	if containsWord(words, "すごい") {
+		t.Error("default classes not pass")
+	}
+
+	if err := g.SetCharClasses("Hiragana", "[\\x{30a0}-\\x{30ff}]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.SetCharClasses("Unknown"); err == nil {
+		t.Error("unknown class not pass")
+	}
+	words = g.Cut(s)
+	t.Log("分词结果：", strings.Join(words, "/"))
+	if !containsWord(words, "すごい") || !containsWord(words, "！") {
+		t.Error("extra classes not pass")
+	}
+
+	s = "我学カタカナ和한국어"
+	t.Log("原始语句：", s)
+	if err := g.SetCharClasses("Hiragana", "Katakana", "Hangul"); err != nil {
+		t.Fatal(err)
+	}
+	for _, mode := range []CutMode{ModeAccurate, ModeFull, ModeNoHMM, ModeSearch, ModeBiMM} {
+		words := tokenizer.TokenTexts(g.CutWith(s, CutOptions{Mode: mode}))
+		t.Log(mode, "分词结果：", strings.Join(words, "/"))
+		if !containsWord(words, "カタカナ") || !containsWord(words, "한국어") {
+			t.Error(mode, "extra classes not pass")
+		}
+	}
+
+	class, _ := tokenizer.NewCharClass()
+	for _, r := range []string{"〇", "𠀀", "㐀", "豈"} {
+		if !class.IsChineseChars(r) {
+			t.Error(r + " not pass")
+		}
+	}
+}
+
+func TestCharClassOptions(t *testing.T) {
+	s := "我会用C++和C#写代码，增长了3.5%"
+	t.Log("原始语句：", s)
+
+	g := NewJieBaGo()
+	err := g.SetCharClassOptions(tokenizer.CharClassOptions{
+		Number: `[a-zA-Z0-9]+(\+\+|#)?(\.\d+)?%?`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := g.Cut(s)
+	t.Log("分词结果：", strings.Join(words, "/"))
+	for _, word := range []string{"C++", "C#", "3.5%"} {
+		if !containsWord(words, word) {
+			t.Error(word + " not pass")
+		}
+	}
+
+	for _, opts := range []tokenizer.CharClassOptions{
+		{Text: "[a-z"},
+		{Delimiter: `\s*`},
+		{Extra: []string{"[^a]"}},
+	} {
+		if err := g.SetCharClassOptions(opts); err == nil {
+			t.Error("validation not pass")
+		}
+	}
+	if !containsWord(g.Cut(s), "C++") {
+		t.Error("invalid options changed the classification")
+	}
+}
+
+func TestNumericEntities(t *testing.T) {
+	s := "2022年3月5日下午3点半，花了三千五百元买了12.5公斤苹果，增长了3.5%"
+	t.Log("原始语句：", s)
+
+	expected := map[string]tokenizer.Token{
+		"2022年3月5日": {Type: tokenizer.EntityDate, Value: "2022-03-05"},
+		"下午3点半":     {Type: tokenizer.EntityTime, Value: "15:30"},
+		"三千五百元":     {Type: tokenizer.EntityMoney, Value: "3500 CNY"},
+		"12.5公斤":    {Type: tokenizer.EntityQuantity, Value: "12.5 kg"},
+		"3.5%":      {Type: tokenizer.EntityNumber, Value: "3.5%"},
+	}
+
+	g := NewJieBaGo()
+	g.SetNumericEntities(true)
+	tokens := g.CutTokens(s, ModeAccurate)
+	t.Log("分词结果：", tokens)
+	found := 0
+	for _, token := range tokens {
+		if v, ok := expected[token.Text]; ok {
+			if v.Type != token.Type || v.Value != token.Value {
+				t.Error(token.Text + " not pass")
+			}
+			found++
+		}
+	}
+	if found != len(expected) {
+		t.Error("numeric entities not pass")
+	}
+	if !containsWord(g.CutForSearch(s), "三千五百元") {
+		t.Error("numeric entities in search mode not pass")
+	}
+
+	for text, value := range map[string]string{
+		"三千五": "3500", "一万零五": "10005", "二〇二二": "2022", "三点一四": "3.14", "五万亿": "5000000000000",
+		"一九九八": "1998",
+	} {
+		if v, ok := tokenizer.ParseChineseNumber(text); !ok || v != value {
+			t.Error(text + " not pass")
+		}
+	}
+	for _, text := range []string{"千万", "万一", "三五千", "七八", "三四"} {
+		if _, ok := tokenizer.ParseChineseNumber(text); ok {
+			t.Error(text + " not pass")
+		}
+	}
+
+	for _, text := range []string{"七八个", "三四天"} {
+		if entities := g.ExtractNumericEntities(text); len(entities) != 0 {
+			t.Log("实体：", entities)
+			t.Error(text + " not pass")
+		}
+	}
+
+	for text, value := range map[string]string{
+		"晚上十二点": "00:00", "夜里12点": "00:00", "凌晨12点半": "00:30", "中午12点": "12:00",
+		"晚上8点": "20:00", "23:59": "23:59",
+	} {
+		entities := g.ExtractNumericEntities(text)
+		if len(entities) != 1 || entities[0].Type != tokenizer.EntityTime || entities[0].Value != value {
+			t.Log("实体：", entities)
+			t.Error(text + " not pass")
+		}
+	}
+	for _, text := range []string{"24点", "24:00", "晚上25点"} {
+		for _, entity := range g.ExtractNumericEntities(text) {
+			if entity.Type == tokenizer.EntityTime {
+				t.Log("实体：", entity)
+				t.Error(text + " not pass")
+			}
+		}
+	}
+
+	entities := g.ExtractNumericEntities("iPhone13在2022-03-05发布，一点儿也不贵")
+	t.Log("实体：", entities)
+	if len(entities) != 1 || entities[0].Value != "2022-03-05" {
+		t.Error("entity boundaries not pass")
+	}
+}
+
+func TestExtractEntities(t *testing.T) {
+	sentences := map[string]map[string]string{
+		"王泽宾在北京市海淀区的编程宝库工作": {
+			"王泽宾": tokenizer.EntityPerson, "北京市海淀区": tokenizer.EntityLocation,
+			"编程宝库": tokenizer.EntityOrganization,
+		},
+		"王小明在北京市海淀区工作": {
+			"王小明": tokenizer.EntityPerson, "北京市海淀区": tokenizer.EntityLocation,
+		},
+		"欧阳娜娜和李明毕业于北京大学": {
+			"欧阳娜娜": tokenizer.EntityPerson, "李明": tokenizer.EntityPerson,
+			"北京大学": tokenizer.EntityOrganization,
+		},
+	}
+	for s, expected := range sentences {
+		t.Log("原始语句：", s)
+		entities := jieBaGo.ExtractEntities(s)
+		t.Log("实体：", entities)
+		for _, entity := range entities {
+			if s[entity.Start:entity.End] != entity.Text || expected[entity.Text] != entity.Type {
+				t.Error(entity.Text + " not pass")
+			}
+		}
+		if len(entities) != len(expected) {
+			t.Error("extract entities not pass")
+		}
+	}
+
+	if entities := jieBaGo.ExtractEntities("王说他明天来"); len(entities) != 0 {
+		t.Error("王说 not pass")
+	}
+	entities := jieBaGo.ExtractEntities("李娜说她来自湖北省武汉市")
+	t.Log("实体：", entities)
+	for _, entity := range entities {
+		if entity.Text != "李娜" && entity.Type == tokenizer.EntityPerson {
+			t.Error(entity.Text + " not pass")
+		}
+	}
+
+	err := jieBaGo.AddEntity("码农大会", "EVENT")
+	if err != nil {
+		t.Error(err)
+	}
+	entities = jieBaGo.ExtractEntities("我们去参加码农大会")
+	if len(entities) != 1 || entities[0].Text != "码农大会" || entities[0].Type != "EVENT" {
+		t.Error("add entity not pass")
+	}
+}
+
+func TestMixedRule(t *testing.T) {
+	s := "苹果发布iPhone14Pro和v1.2.3版本，GPT-4与C++"
+	t.Log("原始语句：", s)
+
+	latinWords := func(g *JieBaGo, mode CutMode) []string {
+		var latin []string
+		for _, word := range tokenizer.TokenTexts(g.CutTokens(s, mode)) {
+			if !tokenizer.IsChineseChars(word) && word != "，" {
+				latin = append(latin, word)
+			}
+		}
+		return latin
+	}
+
+	// the runs are the same words in all the modes without a rule
+	keep := []string{"iPhone14Pro", "v1.2.3", "GPT-4", "C++"}
+	for mode := ModeAccurate; mode <= ModeBiMM; mode++ {
+		if latin := latinWords(jieBaGo, mode); !reflect.DeepEqual(latin, keep) {
+			t.Log(mode, "分词结果：", latin)
+			t.Error("default " + mode.String() + " not pass")
+		}
+	}
+
+	expected := map[tokenizer.MixedRule][]string{
+		tokenizer.MixedKeep:  keep,
+		tokenizer.MixedSplit: {"i", "Phone", "14", "Pro", "v", "1.2.3", "GPT", "-", "4", "C", "++"},
+		tokenizer.MixedBoth:  keep,
+	}
+	g := NewJieBaGo()
+	for rule, words := range expected {
+		g.SetMixedRule(rule)
+		for mode := ModeAccurate; mode <= ModeBiMM; mode++ {
+			if mode == ModeSearch && rule == tokenizer.MixedBoth {
+				continue
+			}
+			if !reflect.DeepEqual(latinWords(g, mode), words) {
+				t.Error(rule.String() + " " + mode.String() + " not pass")
+			}
+		}
+	}
+
+	g.SetMixedRule(tokenizer.MixedBoth)
+	words := g.CutForSearch(s)
+	t.Log("分词结果：", words)
+	for _, word := range []string{"Phone", "14", "Pro", "iPhone14Pro", "GPT", "GPT-4"} {
+		if !containsWord(words, word) {
+			t.Error(word + " not pass")
+		}
+	}
+
+	parts := tokenizer.SplitMixed("HTTPServer2")
+	if !reflect.DeepEqual(parts, []string{"HTTP", "Server", "2"}) {
+		t.Error("split mixed not pass")
+	}
+}
+
+func TestEnglishFilter(t *testing.T) {
+	s := "Shell位于用户与系统之间，shells和SHELL都是命令解释器，connections不稳定"
+	t.Log("原始语句：", s)
+
+	g := NewJieBaGo()
+	words := g.CutForSearch(s)
+	if !containsWord(words, "Shell") || !containsWord(words, "SHELL") {
+		t.Error("english filter disabled not pass")
+	}
+
+	g.SetEnglishFilter(&tokenizer.DefaultEnglishFilter)
+	words = g.CutForSearch(s)
+	t.Log("分词结果：", words)
+	for _, word := range []string{"Shell", "shells", "SHELL", "connections"} {
+		if containsWord(words, word) {
+			t.Error(word + " not pass")
+		}
+	}
+	if !containsWord(words, "shell") || !containsWord(words, "connect") {
+		t.Error("english filter not pass")
+	}
+
+	keywords := g.ExtractKeywordsWeight(s, 5)
+	t.Log("关键词：", keywords)
+	if len(keywords) == 0 || keywords[0].Word != "shell" {
+		t.Error("english filter keywords not pass")
+	}
+
+	stems := map[string]string{
+		"caresses": "caress", "ponies": "poni", "hopping": "hop", "relational": "relat",
+		"generalization": "gener", "running": "run", "roll": "roll", "C++": "C++",
+	}
+	for word, stem := range stems {
+		if tokenizer.Stem(word) != stem {
+			t.Error(word + " not pass")
+		}
+	}
+}
+
+func TestTokenFilter(t *testing.T) {
+	s := "Shell位于用户与系统之间，用来帮助用户与操作系统进行沟通。"
+	t.Log("原始语句：", s)
+
+	words := jieBaGo.CutWithFilter(s, ModeAccurate, tokenizer.TokenFilter{Punctuation: true})
+	t.Log("分词结果：", words)
+	if containsWord(words, "，") || containsWord(words, "。") || !containsWord(words, "与") {
+		t.Error("punctuation filter not pass")
+	}
+
+	words = jieBaGo.CutWithFilter(s, ModeSearch, tokenizer.TokenFilter{StopWords: true, SingleChars: true})
+	t.Log("分词结果：", words)
+	if containsWord(words, "与") || containsWord(words, "用来") || !containsWord(words, "操作系统") {
+		t.Error("stop words filter not pass")
+	}
+
+	g := NewJieBaGo()
+	g.SetTokenFilter(&tokenizer.TokenFilter{Punctuation: true, SingleChars: true})
+	words = g.Cut(s)
+	t.Log("分词结果：", words)
+	for _, word := range words {
+		if len([]rune(word)) < 2 {
+			t.Error(word + " not pass")
+		}
+	}
+	if !reflect.DeepEqual(words, tokenizer.TokenTexts(g.CutTokens(s, ModeAccurate))) {
+		t.Error("instance filter not pass")
+	}
+	if !containsWord(jieBaGo.Cut(s), "，") {
+		t.Error("default filter not pass")
+	}
+}
+
+func TestTokenFilterCoverage(t *testing.T) {
+	g := NewJieBaGo()
+	g.SetTokenFilter(&tokenizer.TokenFilter{StopWords: true, Punctuation: true})
+	t.Log("原始语句：", sentence)
+
+	words := []string{"Shell", "位于", "用户", "与", "系统", "之间", "，", "用来", "帮助",
+		"用户", "与", "操作", "系统", "进行", "沟通", "。"}
+	if _, _, err := g.ScoreSegmentation(sentence, words); err != nil {
+		t.Error(err)
+	}
+
+	tokens := g.ExpandSynonyms("我的电脑")
+	t.Log("分词结果：", tokens)
+	if len(tokens) == 0 || tokens[0].Text != "我" || tokens[1].Text != "的" {
+		t.Error("ExpandSynonyms not pass")
+	}
+
+	fragments := g.Highlight(sentence, "用户", &HighlightOptions{FragmentSize: 12})
+	t.Log("高亮结果：", fragments)
+	if !reflect.DeepEqual(fragments, NewJieBaGo().Highlight(sentence, "用户", &HighlightOptions{FragmentSize: 12})) {
+		t.Error("Highlight not pass")
+	}
+}
+
+func TestCutWith(t *testing.T) {
+	s := "Shell位于用户与系统之间，用来帮助用户与操作系统进行沟通。"
+	t.Log("原始语句：", s)
+
+	modes := map[CutMode]func(string) []string{
+		ModeAccurate: jieBaGo.CutAccurate, ModeFull: jieBaGo.CutFull, ModeNoHMM: jieBaGo.CutNoHMM,
+		ModeSearch: jieBaGo.CutForSearch, ModeFMM: jieBaGo.CutFMM, ModeBMM: jieBaGo.CutBMM,
+		ModeBiMM: jieBaGo.CutBiMM,
+	}
+	for mode, cut := range modes {
+		if !reflect.DeepEqual(tokenizer.TokenTexts(jieBaGo.CutWith(s, CutOptions{Mode: mode})), cut(s)) {
+			t.Error(mode.String() + " not pass")
+		}
+	}
+
+	words := tokenizer.TokenTexts(jieBaGo.CutWith(s, CutOptions{NoHMM: true}))
+	if !reflect.DeepEqual(words, jieBaGo.CutNoHMM(s)) {
+		t.Error("no hmm not pass")
+	}
+
+	tokens := jieBaGo.CutWith(s, CutOptions{RuneOffsets: true})
+	t.Log("分词结果：", tokens)
+	runes := []rune(s)
+	for _, token := range tokens {
+		if string(runes[token.Start:token.End]) != token.Text {
+			t.Error(token.Text + " not pass")
+		}
+	}
+
+	var opts CutOptions
+	err := json.Unmarshal([]byte(`{"mode":"search","search":{"max_gram":2},"filter":{"punctuation":true}}`), &opts)
+	if err != nil {
+		t.Error(err)
+	}
+	words = tokenizer.TokenTexts(jieBaGo.CutWith(s, opts))
+	t.Log("分词结果：", words)
+	if opts.Mode != ModeSearch || containsWord(words, "，") || containsWord(words, "操作系") ||
+		!containsWord(words, "操作") {
+		t.Error("json options not pass")
+	}
+
+	g := NewJieBaGo()
+	g.SetTokenFilter(&tokenizer.TokenFilter{Punctuation: true})
+	words = tokenizer.TokenTexts(g.CutWith(s, CutOptions{Filter: &tokenizer.TokenFilter{}}))
+	if !containsWord(words, "，") {
+		t.Error("filter option not pass")
+	}
+}
+
+func TestCutWithInstanceFilter(t *testing.T) {
+	g := NewJieBaGo()
+	g.SetTokenFilter(&tokenizer.TokenFilter{StopWords: true, Punctuation: true})
+
+	s := "<p>用户与系统之间的沟通</p>"
+	t.Log("原始语句：", s)
+	tokens := g.CutMarkup(s, tokenizer.MarkupHTML, ModeAccurate)
+	t.Log("分词结果：", tokens)
+	for _, token := range tokens {
+		if token.Text == "的" {
+			t.Error("CutMarkup not pass")
+		}
+	}
+
+	tokens = g.CutTraditional("用戶與系統之間的溝通。", ModeAccurate)
+	t.Log("分词结果：", tokens)
+	texts := tokenizer.TokenTexts(tokens)
+	if containsWord(texts, "的") || containsWord(texts, "。") || !containsWord(texts, "溝通") {
+		t.Error("CutTraditional not pass")
+	}
+}
+
+func TestConcurrentSettings(t *testing.T) {
+	g := &JieBaGo{}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			g.SetMaxWordLen(i)
+			g.SetMixedRule(tokenizer.MixedRule(i % 3))
+			g.SetEnglishFilter(&tokenizer.EnglishFilter{LowerCase: i%2 == 0})
+			g.SetTokenFilter(&tokenizer.TokenFilter{StopWords: i%2 == 0})
+			g.SetNormalizeOptions(&tokenizer.NormalizeOptions{FullWidth: i%2 == 0})
+			g.SetNumericEntities(i%2 == 0)
+			g.SetBigramModel(nil)
+			g.SetRecognizer(nil)
+		}(i)
+		go func() {
+			defer wg.Done()
+			if tokens := g.CutWith(sentence, CutOptions{Mode: ModeSearch}); len(tokens) == 0 {
+				t.Error("concurrent CutWith not pass")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSearchOptions(t *testing.T) {
+	s := "Shell位于用户与系统之间，用来帮助用户与操作系统进行沟通。"
+	t.Log("原始语句：", s)
+
+	g := NewJieBaGo()
+	tokens := g.CutTokens(s, ModeSearch)
+	t.Log("分词结果：", tokenizer.TokenTexts(tokens))
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i].Start < tokens[i-1].Start {
+			t.Error(tokens[i].Text + " not pass")
+		}
+	}
+	if !containsWord(tokenizer.TokenTexts(tokens), "操作系") {
+		t.Error("default search options not pass")
+	}
+
+	g.SetSearchOptions(&tokenizer.SearchOptions{MaxGram: 2, SingleChars: true, NoParent: true})
+	words := g.CutForSearch(s)
+	t.Log("分词结果：", words)
+	if containsWord(words, "操作系统") || containsWord(words, "操作系") || !containsWord(words, "操作") ||
+		!containsWord(words, "统") || !containsWord(words, "与") || !containsWord(words, "Shell") {
+		t.Error("search options not pass")
+	}
+
+	words = tokenizer.TokenTexts(g.CutWith(s, CutOptions{Mode: ModeSearch, Search: &tokenizer.DefaultSearchOptions}))
+	if !containsWord(words, "操作系统") || containsWord(words, "统") {
+		t.Error("search options of the call not pass")
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	dir := t.TempDir()
+	synonyms := "# 同义词\n电脑, 计算机\n手机 => 移动电话\n"
+	err := os.WriteFile(filepath.Join(dir, "synonyms.txt"), []byte(synonyms), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := `{"analyzers": {
+		"search": {
+			"char_filters": [{"type": "normalize"}, {"type": "html"}],
+			"tokenizer": {"mode": "search"},
+			"filters": [{"type": "lowercase"}, {"type": "punctuation"}, {"type": "stop"},
+				{"type": "synonym", "path": "synonyms.txt"}, {"type": "length", "min": 2}]
+		},
+		"nouns": {"filters": [{"type": "pos", "keep": ["n"]}]}
+	}}`
+	file := filepath.Join(dir, "analysis.json")
+	err = os.WriteFile(file, []byte(config), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewJieBaGo()
+	if err = g.LoadAnalyzers(file); err != nil {
+		t.Fatal(err)
+	}
+
+	s := "<p>ＳＨＥＬＬ用户的电脑与手机</p>"
+	t.Log("原始语句：", s)
+	tokens, err := g.Analyze(s, "search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("分词结果：", tokens)
+	positions := make(map[string]int)
+	for _, token := range tokens {
+		positions[token.Text] = token.Position
+		if len([]rune(token.Text)) < 2 || token.Text == "的" || strings.Contains(token.Text, "<") {
+			t.Error(token.Text + " not pass")
+		}
+	}
+	if _, ok := positions["shell"]; !ok {
+		t.Error("char filters not pass")
+	}
+	if p, ok := positions["计算机"]; !ok || p != positions["电脑"] {
+		t.Error("equivalent synonyms not pass")
+	}
+	if _, ok := positions["手机"]; ok || positions["移动电话"] == 0 {
+		t.Error("explicit synonyms not pass")
+	}
+	for _, token := range tokens {
+		if token.Text == "电脑" && s[token.Start:token.End] != "电脑" {
+			t.Error("offsets not pass")
+		}
+	}
+
+	tokens, _ = g.Analyze("用户与系统之间", "nouns")
+	t.Log("分词结果：", tokens)
+	for _, token := range tokens {
+		if !strings.HasPrefix(tokenizer.GetDictionary().GetProp(token.Text), "n") {
+			t.Error(token.Text + " not pass")
+		}
+	}
+
+	if _, err = g.Analyze(s, "unknown"); err == nil {
+		t.Error("unknown analyzer not pass")
+	}
+	if err = g.LoadAnalyzers(file + ".missing"); err == nil {
+		t.Error("missing config not pass")
+	}
+	invalid := filepath.Join(dir, "invalid.json")
+	err = os.WriteFile(invalid, []byte(`{"analyzers": {"a": {"tokenizer": {"mode": "serach"}}}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = g.LoadAnalyzers(invalid); err == nil || g.GetAnalyzer("a") != nil {
+		t.Error("unknown mode not pass")
+	} else {
+		t.Log(err)
+	}
+
+	// the sub-words of the search mode are at the position of their word
+	tokens = g.AnalyzeWith("中华人民共和国成立了", &Analyzer{Options: CutOptions{Mode: ModeSearch}})
+	t.Log("分词结果：", tokens)
+	positions = make(map[string]int)
+	for _, token := range tokens {
+		positions[token.Text] = token.Position
+	}
+	for _, word := range []string{"中华", "华人", "人民", "共和国"} {
+		if p, ok := positions[word]; !ok || p != positions["中华人民共和国"] {
+			t.Error(word + " position not pass")
+		}
+	}
+	if positions["成立"] != positions["中华人民共和国"]+1 || positions["了"] != positions["成立"]+1 {
+		t.Error("position increment not pass")
+	}
+}
+
+func TestExpandSynonyms(t *testing.T) {
+	s := "我想买一台笔记本电脑"
+	t.Log("原始语句：", s)
+	tokens := jieBaGo.ExpandSynonyms(s)
+	t.Log("分词结果：", tokens)
+	positions := make(map[string]int)
+	for _, token := range tokens {
+		positions[token.Text] = token.Position
+	}
+	if p, ok := positions["计算机"]; !ok || p != positions["电脑"] {
+		t.Error("电脑 not pass")
+	}
+	if p, ok := positions["笔记本电脑"]; !ok || p != positions["笔记本"] {
+		t.Error("笔记本 not pass")
+	}
+	for _, token := range tokens {
+		if token.Text == "计算机" && s[token.Start:token.End] != "电脑" {
+			t.Error("offsets not pass")
+		}
+	}
+
+	synonyms := jieBaGo.GetSynonyms("计算机")
+	t.Log("同义词：", synonyms)
+	if !containsWord(synonyms, "电脑") {
+		t.Error("GetSynonyms not pass")
+	}
+}
+
+func TestAddSynonyms(t *testing.T) {
+	rules := []string{"jiebago, 结巴分词", "电脑, 计算机"}
+	t.Log("加入同义词：", rules)
+	for _, rule := range rules {
+		exist, err := jieBaGo.AddSynonyms(rule)
+		if err != nil {
+			t.Error(err)
+		} else {
+			if exist {
+				t.Log(rule + " 已经存在")
+			} else {
+				t.Log(rule + " 添加入库")
+			}
+		}
+	}
+	if !containsWord(jieBaGo.GetSynonyms("JieBaGo"), "结巴分词") {
+		t.Error("jiebago not pass")
+	}
+	if _, err := jieBaGo.AddSynonyms("电脑"); err == nil {
+		t.Error("invalid rule not pass")
+	}
+}
+
+func TestDetectSensitive(t *testing.T) {
+	s := "他在网上赌 * 博，还参与網絡賭場，ＳＢ不是usb。"
+	t.Log("原始语句：", s)
+	words := jieBaGo.DetectSensitive(s)
+	t.Log("敏感词：", words)
+	expected := []string{"赌博", "网络赌场", "sb"}
+	if len(words) != len(expected) {
+		t.Fatal("DetectSensitive not pass")
+	}
+	for i, word := range words {
+		if word.Value != expected[i] || s[word.Start:word.End] != word.Text {
+			t.Error(word.Text + " not pass")
+		}
+	}
+	if words[0].Type != "GAMBLING" || words[2].Type != "ABUSE" {
+		t.Error("category not pass")
+	}
+
+	masked := jieBaGo.MaskSensitive(s, "")
+	t.Log("屏蔽结果：", masked)
+	if masked != "他在网上*****，还参与****，**不是usb。" {
+		t.Error("MaskSensitive not pass")
+	}
+	replaced := jieBaGo.ReplaceSensitive("网上赌博", "[已删除]")
+	if replaced != "网上[已删除]" {
+		t.Error("ReplaceSensitive not pass")
+	}
+
+	w := tokenizer.NewSensitiveWords()
+	if err := w.AddWord("刷 单", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddWord("**", ""); err == nil {
+		t.Error("empty word not pass")
+	}
+	w.SetMaxSkip(0)
+	if words = w.FindAll("刷单和刷-单"); len(words) != 1 || words[0].Type != tokenizer.SensitiveDefault {
+		t.Error("SetMaxSkip not pass")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	doc := "Shell位于用户与系统之间，用来帮助用户与操作系统进行沟通。这样做能使用户更方便。我想买一台笔记本电脑。"
+	t.Log("原始语句：", doc)
+	fragments := jieBaGo.Highlight(doc, "用户", &HighlightOptions{FragmentSize: 20, Fragments: 2})
+	t.Log("高亮结果：", fragments)
+	if len(fragments) == 0 || !strings.Contains(fragments[0].Text, "<em>用户</em>") {
+		t.Fatal("Highlight not pass")
+	}
+	for _, fragment := range fragments {
+		if fragment.Score < 1 || len(fragment.Matches) == 0 {
+			t.Error("score not pass")
+		}
+		for _, match := range fragment.Matches {
+			if doc[match.Start:match.End] != "用户" {
+				t.Error(match.Text + " not pass")
+			}
+		}
+	}
+	if len(fragments) > 1 && fragments[0].Score < fragments[1].Score {
+		t.Error("order not pass")
+	}
+
+	fragments = jieBaGo.Highlight(doc, "笔记本", &HighlightOptions{PreTag: "[", PostTag: "]"})
+	t.Log("高亮结果：", fragments)
+	if len(fragments) != 1 || !strings.Contains(fragments[0].Text, "[笔记本]电脑") {
+		t.Error("tags not pass")
+	}
+	s := "研究生命起源的研究生很多"
+	fragments = jieBaGo.Highlight(s, "研究生", nil)
+	t.Log("高亮结果：", fragments)
+	if len(fragments) != 1 || strings.Contains(fragments[0].Text, "<em>研究生</em>命") ||
+		!strings.Contains(fragments[0].Text, "<em>研究生</em>很多") {
+		t.Error("word boundaries not pass")
+	}
+	// 研究生 is in the text of 研究生命, but not one of its words
+	s = "研究生命起源"
+	words := jieBaGo.CutForSearch(s)
+	fragments = jieBaGo.Highlight(s, "研究生", nil)
+	t.Log("分词结果：", words)
+	t.Log("高亮结果：", fragments)
+	for _, fragment := range fragments {
+		for _, match := range fragment.Matches {
+			if match.Text == "研究生" || !containsWord(words, match.Text) {
+				t.Error(match.Text + " not pass")
+			}
+		}
+	}
+	if fragments := jieBaGo.Highlight(doc, "的", nil); len(fragments) != 0 {
+		t.Error("stop words not pass")
+	}
+}
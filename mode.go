@@ -0,0 +1,86 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jiebago
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wangshizebin/jiebago/tokenizer"
+)
+
+// CutMode is the way a sentence is cut into words
+type CutMode int
+
+const (
+	ModeAccurate CutMode = iota // the most probable route with HMM for unknown words, the default
+	ModeFull                    // all the dictionary words
+	ModeNoHMM                   // the most probable route without HMM
+	ModeSearch                  // the accurate mode plus the dictionary words inside long words
+	ModeFMM                     // forward maximum matching
+	ModeBMM                     // backward maximum matching
+	ModeBiMM                    // bidirectional maximum matching
+)
+
+var modeNames = []string{"accurate", "full", "nohmm", "search", "fmm", "bmm", "bimm"}
+
+func (m CutMode) String() string {
+	if m < 0 || int(m) >= len(modeNames) {
+		return modeNames[ModeAccurate]
+	}
+	return modeNames[m]
+}
+
+// ParseCutMode returns the mode of the name, such as "full" or "search". The
+// accurate mode is returned for an empty or unknown name.
+func ParseCutMode(name string) CutMode {
+	m, _ := lookupCutMode(name)
+	return m
+}
+
+// Look up the mode of the name, the accurate mode of an empty name, false is
+// returned for an unknown name
+func lookupCutMode(name string) (CutMode, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return ModeAccurate, true
+	}
+	for i, v := range modeNames {
+		if v == name {
+			return CutMode(i), true
+		}
+	}
+	return ModeAccurate, false
+}
+
+// MarshalText returns the name of the mode
+func (m CutMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText sets the mode of the name, an empty name is the accurate mode
+// and an unknown name is an error
+func (m *CutMode) UnmarshalText(text []byte) error {
+	mode, ok := lookupCutMode(string(text))
+	if !ok {
+		return fmt.Errorf("unknown cut mode %q", text)
+	}
+	*m = mode
+	return nil
+}
+
+// CutOptions are the options of CutWith. The zero value cuts in the accurate mode
+// with the settings of the instance, a nil option uses the setting of the instance.
+type CutOptions struct {
+	Mode        CutMode                     `json:"mode"`
+	NoHMM       bool                        `json:"no_hmm"`       // no HMM for unknown words in the accurate and search modes
+	Search      *tokenizer.SearchOptions    `json:"search"`       // sub-words of the search mode, the ones of the instance if nil
+	Filter      *tokenizer.TokenFilter      `json:"filter"`       // filter of the words, the one of the instance if nil
+	Normalize   *tokenizer.NormalizeOptions `json:"normalize"`    // normalization of the text, the one of the instance if nil
+	Spans       []tokenizer.Span            `json:"spans"`        // spans kept as single tokens
+	RuneOffsets bool                        `json:"rune_offsets"` // Start and End of the tokens in runes instead of bytes
+
+	cutText func(string, int, *[]tokenizer.Token) // cutter of the text segments, the one of the mode if nil
+}
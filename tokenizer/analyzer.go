@@ -243,7 +243,11 @@ func (t *TFIDF) ExtractKeywords(s string, count int, withWeight bool) interface{
 			CutSymbolW(segment, &words)
 		}
 	}
+	return t.ExtractKeywordsFromWords(words, count, withWeight)
+}
 
+// ExtractKeywordsFromWords extracts keywords from the words of a text which is already cut
+func (t *TFIDF) ExtractKeywordsFromWords(words []string, count int, withWeight bool) interface{} {
 	freqMap, freqMedian := t.idfLoader.idfFreq, t.idfLoader.idfMedian
 
 	freqTotal := 0
@@ -265,7 +269,7 @@ func (t *TFIDF) ExtractKeywords(s string, count int, withWeight bool) interface{
 	wordsRet := make(Keywords, len(freqWords))
 	for word, s := range freqWords {
 		val := freqMedian
-		if v, ok := freqMap[word]; ok {
+		if v, ok := freqMap[strings.ToLower(word)]; ok {
 			val = v
 		}
 		wordsRet[i] = Keyword{
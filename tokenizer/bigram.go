@@ -0,0 +1,212 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	BigramBegin    = "<s>" // context of the first word of a sentence
+	bigramDiscount = 0.5   // absolute discount of the seen word pairs
+)
+
+// BigramModel scores a word by the word in front of it. The probabilities are
+// estimated from word-pair counts with absolute discounting, and word pairs not
+// in the model back off to the unigram model of the dictionary.
+type BigramModel struct {
+	pairs    map[string]map[string]int
+	contexts map[string]int // total count of the pairs starting with a word
+}
+
+func NewBigramModel() *BigramModel {
+	return &BigramModel{
+		pairs:    make(map[string]map[string]int),
+		contexts: make(map[string]int),
+	}
+}
+
+// LoadBigramModel loads a file of word pairs, one "word1 word2 count" per line.
+// The word1 of the first word of a sentence is <s>.
+func LoadBigramModel(file string) (*BigramModel, error) {
+	m := NewBigramModel()
+	if err := m.load(file); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Add adds the count of a word pair
+func (m *BigramModel) Add(word1, word2 string, count int) {
+	if count <= 0 {
+		return
+	}
+	word1, word2 = strings.ToLower(word1), strings.ToLower(word2)
+	next, ok := m.pairs[word1]
+	if !ok {
+		next = make(map[string]int)
+		m.pairs[word1] = next
+	}
+	next[word2] += count
+	m.contexts[word1] += count
+}
+
+// LogProb returns the log probability of word2 following word1
+func (m *BigramModel) LogProb(word1, word2 string) float64 {
+	word1, word2 = strings.ToLower(word1), strings.ToLower(word2)
+	unigram := unigramLogProb(word2)
+
+	total, ok := m.contexts[word1]
+	if !ok {
+		return unigram
+	}
+	next := m.pairs[word1]
+	if count, ok := next[word2]; ok {
+		return math.Log((float64(count) - bigramDiscount) / float64(total))
+	}
+	// the mass taken from the seen pairs is left to the unigram model
+	return math.Log(bigramDiscount*float64(len(next))/float64(total)) + unigram
+}
+
+func (m *BigramModel) load(file string) error {
+	timeStart := time.Now()
+
+	f, err := os.Open(file)
+	if err != nil {
+		log.Println(err)
+		return errors.New("unable to load the bigram model:" + filepath.Base(file))
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	itemCount := 0
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			break
+		}
+
+		elem := strings.Fields(line)
+		if len(elem) != 3 {
+			if err == io.EOF {
+				break
+			}
+			continue
+		}
+
+		count, errCount := strconv.Atoi(elem[2])
+		if errCount == nil && count > 0 {
+			itemCount++
+			m.Add(elem[0], elem[1], count)
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+	if itemCount == 0 {
+		return errors.New("unable to load the bigram model:" + filepath.Base(file))
+	}
+
+	log.Printf("%v word pairs are loaded in bigram model "+filepath.Base(file)+", and take %v\n",
+		itemCount, time.Now().Sub(timeStart))
+	return nil
+}
+
+// The score of a word in the unigram model, the same as CalcDAG
+func unigramLogProb(word string) float64 {
+	dictionary := GetDictionary()
+	freq, _ := dictionary.GetWord(word)
+	if freq <= 0 {
+		freq = 1
+	}
+	return math.Log(float64(freq)) - math.Log(dictionary.GetTotalFreq())
+}
+
+// CalcDAGBigram finds the best route over the DAG with a Viterbi search under the
+// bigram model. The route has the same form as the one of CalcDAG, but only the
+// positions on the best route are filled.
+func (s *Sentence) CalcDAGBigram(m *BigramModel) []NodeDAG {
+	n := s.Len()
+	route := make([]NodeDAG, n+1)
+	route[n] = NodeDAG{0, 0}
+	if n == 0 {
+		return route
+	}
+
+	// incoming[k] lists the starts of the words ending at k
+	dag := s.GetDAG()
+	incoming := make([][]int, n+1)
+	for k, ends := range dag {
+		for _, x := range ends {
+			incoming[x+1] = append(incoming[x+1], k)
+		}
+	}
+
+	// score[k][x] is the best score of the sentence prefix which ends with word [k, x]
+	type state struct {
+		score float64
+		prev  int
+	}
+	score := make([]map[int]state, n)
+	for k := 0; k < n; k++ {
+		score[k] = make(map[int]state, len(dag[k]))
+		for _, x := range dag[k] {
+			word := s.GetWord(k, x+1)
+			if k == 0 {
+				score[k][x] = state{m.LogProb(BigramBegin, word), -1}
+				continue
+			}
+
+			best := state{prev: -1}
+			for _, p := range incoming[k] {
+				prev, ok := score[p][k-1]
+				if !ok {
+					continue
+				}
+				val := prev.score + m.LogProb(s.GetWord(p, k), word)
+				if best.prev == -1 || val >= best.score {
+					best = state{val, p}
+				}
+			}
+			if best.prev != -1 {
+				score[k][x] = best
+			}
+		}
+	}
+
+	// find the best word ending the sentence, and follow it back
+	last, total := -1, float64(0)
+	for _, k := range incoming[n] {
+		v, ok := score[k][n-1]
+		if ok && (last == -1 || v.score >= total) {
+			last, total = k, v.score
+		}
+	}
+
+	end := n - 1
+	for k := last; k >= 0; {
+		v := score[k][end]
+		prefix := float64(0)
+		if v.prev >= 0 {
+			prefix = score[v.prev][k-1].score
+		}
+		route[k] = NodeDAG{total - prefix, end}
+		end = k - 1
+		k = v.prev
+	}
+	return route
+}
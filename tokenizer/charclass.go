@@ -0,0 +1,194 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var defaultCharClass = &CharClass{
+	reChinese:   reChinese,
+	reText:      reText,
+	reNumber:    reNumber,
+	reEnglish:   reEnglish,
+	reDelimiter: reDelimiter,
+}
+
+// CharClassOptions are the regular expressions of the character classification, the
+// empty ones are the defaults. The regular expressions must not match empty strings.
+type CharClassOptions struct {
+	Text      string   `json:"text"`      // runs of text characters, RegExpText by default
+	Number    string   `json:"number"`    // numbers and English words in the text passed to the HMM, RegExpNumber by default
+	English   string   `json:"english"`   // English characters joined together, RegExpEnglish by default
+	Delimiter string   `json:"delimiter"` // delimiters of the symbols, RegExpDelimiter by default
+	Extra     []string `json:"extra"`     // extra classes of text characters, such as Hangul or [+#]
+}
+
+// CharClass classifies the characters of a text. The Chinese characters, all the Han
+// characters of Unicode, are cut by the dictionary and the HMM. The text characters
+// are the Chinese characters, the English letters and digits and the extra classes,
+// the runs of the extra classes are kept as words the same way as English words.
+// All the other characters are symbols, they are cut at the delimiters.
+type CharClass struct {
+	extra       []string
+	reExtra     *regexp.Regexp // a character of the extra classes, nil if none
+	reChinese   *regexp.Regexp
+	reText      *regexp.Regexp
+	reNumber    *regexp.Regexp
+	reEnglish   *regexp.Regexp
+	reDelimiter *regexp.Regexp
+}
+
+// NewCharClass returns the classification with the extra classes of text characters.
+// A class is the name of a Unicode script or category, such as Hiragana, Katakana or
+// Hangul, or a character class of regular expressions, such as [\x{3040}-\x{30ff}].
+func NewCharClass(extra ...string) (*CharClass, error) {
+	return NewCharClassWithOptions(CharClassOptions{Extra: extra})
+}
+
+// NewCharClassWithOptions returns the classification with the regular expressions of
+// the options, an error is returned if one of them is invalid.
+func NewCharClassWithOptions(opts CharClassOptions) (*CharClass, error) {
+	if opts.Text == "" && opts.Number == "" && opts.English == "" && opts.Delimiter == "" &&
+		len(opts.Extra) == 0 {
+		return defaultCharClass, nil
+	}
+
+	text := opts.Text
+	if text == "" {
+		text = RegExpText
+	}
+	var reExtra *regexp.Regexp
+	if len(opts.Extra) > 0 {
+		var b strings.Builder
+		for _, v := range opts.Extra {
+			class, err := charClassExpr(v)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(class)
+		}
+		// the extra classes are one more alternative of the text characters
+		text = "(?:" + text + "|[" + b.String() + "]+)+"
+		reExtra = regexp.MustCompile("[" + b.String() + "]")
+	}
+
+	c := &CharClass{
+		extra:     append([]string(nil), opts.Extra...),
+		reExtra:   reExtra,
+		reChinese: reChinese,
+	}
+	exprs := []struct {
+		name string
+		expr string
+		def  *regexp.Regexp
+		re   **regexp.Regexp
+	}{
+		{"text", text, reText, &c.reText},
+		{"number", opts.Number, reNumber, &c.reNumber},
+		{"english", opts.English, reEnglish, &c.reEnglish},
+		{"delimiter", opts.Delimiter, reDelimiter, &c.reDelimiter},
+	}
+	for _, v := range exprs {
+		if v.expr == "" {
+			*v.re = v.def
+			continue
+		}
+		re, err := regexp.Compile(v.expr)
+		if err != nil {
+			return nil, errors.New("invalid " + v.name + " regular expression: " + err.Error())
+		}
+		if re.MatchString("") {
+			return nil, errors.New("the " + v.name + " regular expression matches the empty string")
+		}
+		*v.re = re
+	}
+	return c, nil
+}
+
+// Turn a class name into the content of a regular expression character class
+func charClassExpr(class string) (string, error) {
+	class = strings.TrimSpace(class)
+	if class == "" {
+		return "", errors.New("the character class is empty")
+	}
+	if _, ok := unicode.Scripts[class]; ok {
+		return `\p{` + class + `}`, nil
+	}
+	if _, ok := unicode.Categories[class]; ok {
+		return `\p{` + class + `}`, nil
+	}
+	if strings.HasPrefix(class, "[") && strings.HasSuffix(class, "]") && len(class) > 2 &&
+		!strings.HasPrefix(class, "[^") {
+		inner := class[1 : len(class)-1]
+		if _, err := regexp.Compile("[" + inner + "]"); err != nil {
+			return "", err
+		}
+		return inner, nil
+	}
+	return "", errors.New("unknown character class: " + class)
+}
+
+// Extra returns the extra classes of text characters
+func (c *CharClass) Extra() []string {
+	return append([]string(nil), c.extra...)
+}
+
+// IsChineseChars returns whether there are Chinese characters in the text
+func (c *CharClass) IsChineseChars(s string) bool {
+	return c.reChinese.MatchString(s)
+}
+
+// IsTextChars returns whether there are text characters in the text
+func (c *CharClass) IsTextChars(s string) bool {
+	return c.reText.MatchString(s)
+}
+
+// SplitChineseSeg splits the text into the runs of Chinese characters and the rest
+func (c *CharClass) SplitChineseSeg(s string) []string {
+	return splitRegExp(s, c.reChinese)
+}
+
+// SplitTextSeg splits the text into the runs of text characters and the rest
+func (c *CharClass) SplitTextSeg(s string) []string {
+	return splitRegExp(s, c.reText)
+}
+
+// IsEnglishChars returns whether there are English characters in the text
+func (c *CharClass) IsEnglishChars(s string) bool {
+	return c.reEnglish.MatchString(s)
+}
+
+// the kinds of the runs of characters joined together by the cut modes
+const (
+	runNone    = iota
+	runEnglish // English characters
+	runExtra   // characters of the extra classes
+)
+
+// The kind of the run a character is joined into by the cut modes, so that the runs
+// of the extra classes are words in all the modes, the same way as with the HMM
+func (c *CharClass) runKind(s string) int {
+	if c.IsEnglishChars(s) {
+		return runEnglish
+	}
+	if c.reExtra != nil && c.reExtra.MatchString(s) {
+		return runExtra
+	}
+	return runNone
+}
+
+// SplitNumberSeg splits the text into the numbers and the rest
+func (c *CharClass) SplitNumberSeg(s string) []string {
+	return splitRegExp(s, c.reNumber)
+}
+
+// CutSymbolW cuts the symbols at the delimiters, the same way as CutSymbolW
+func (c *CharClass) CutSymbolW(s string, words *[]string) {
+	cutSymbol(s, c.reDelimiter, words)
+}
@@ -15,17 +15,26 @@ import (
 )
 
 const (
-	DictStdFile       = "dict_std_utf8.txt"        // standard dictionary file
-	DictUserFile      = "dict_user_utf8.txt"       // user-defined dictionary file
-	IDFStdFile        = "idf_std_utf8.txt"         // standard IDF file
-	StopWordsStdFile  = "stop_words_std_utf8.txt"  // standard stop words file
-	StopWordsUserFile = "stop_words_user_utf8.txt" // user-defined stop words file
-
-	RegExpEnglish   = "([a-zA-Z0-9])+"                     // English regular expression
-	RegExpChinese   = "([\u4e00-\u9fa5])+"                 // Chinese regular expression
-	RegExpText      = "([\u4e00-\u9fa5a-zA-Z0-9+#&._%-])+" // text regular expression
-	RegExpNumber    = "[a-zA-Z0-9]+(\\.\\d+)?%?"           // numeric regular expression
-	RegExpDelimiter = "[\\r\\n\\s\\t]"                     // delimiter regular expression
+	DictStdFile        = "dict_std_utf8.txt"        // standard dictionary file
+	DictUserFile       = "dict_user_utf8.txt"       // user-defined dictionary file
+	IDFStdFile         = "idf_std_utf8.txt"         // standard IDF file
+	StopWordsStdFile   = "stop_words_std_utf8.txt"  // standard stop words file
+	StopWordsUserFile  = "stop_words_user_utf8.txt" // user-defined stop words file
+	T2SCharsFile       = "t2s_chars_utf8.txt"       // traditional to simplified characters file
+	T2SPhrasesFile     = "t2s_phrases_utf8.txt"     // traditional to simplified phrases file
+	S2TCharsFile       = "s2t_chars_utf8.txt"       // simplified to traditional characters file
+	S2TPhrasesFile     = "s2t_phrases_utf8.txt"     // simplified to traditional phrases file
+	EntityUserFile     = "entity_user_utf8.txt"     // user-defined entity file
+	SynonymsStdFile    = "synonyms_std_utf8.txt"    // standard synonyms file
+	SynonymsUserFile   = "synonyms_user_utf8.txt"   // user-defined synonyms file
+	SensitiveWordsFile = "sensitive_words_utf8.txt" // sensitive words file
+
+	RegExpEnglish   = "([a-zA-Z0-9])+"                                // English regular expression
+	RegExpChinese   = "(\\p{Han})+"                                   // Chinese regular expression, all the Han characters
+	RegExpText      = "([\\p{Han}a-zA-Z0-9+#&._%-])+"                 // text regular expression
+	RegExpNumber    = "[a-zA-Z0-9]+(\\.\\d+)?%?"                      // numeric regular expression
+	RegExpDelimiter = "[\\r\\n\\s\\t]"                                // delimiter regular expression
+	RegExpMixed     = "[a-zA-Z0-9]+([._-][a-zA-Z0-9]+)*(\\+\\+|#|%)?" // mixed Latin and digit regular expression
 
 	DefaultWordsLen = 32 // default slice size of the word segmentation result
 )
@@ -36,6 +45,7 @@ var (
 	reText, _      = regexp.Compile(RegExpText)      // precompiled text regular expression
 	reNumber, _    = regexp.Compile(RegExpNumber)    // precompiled numeric regular expression
 	reDelimiter, _ = regexp.Compile(RegExpDelimiter) // precompiled delimiter regular expression
+	reMixed, _     = regexp.Compile(RegExpMixed)     // precompiled mixed Latin and digit regular expression
 
 	dictPath string // dictionary directory, default is current work directory
 )
@@ -0,0 +1,221 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	t2sConverter = NewConverter() // traditional to simplified converter
+	s2tConverter = NewConverter() // simplified to traditional converter
+)
+
+// Converter converts Chinese text between scripts with the mapping tables in the
+// format of OpenCC: "source<TAB>target1 target2 ..." per line, the first target is
+// used. The phrases are matched first, the longest one wins, then the characters.
+type Converter struct {
+	chars   map[rune]string
+	phrases map[string]string
+	maxLen  int // maximal rune length of the phrases
+	mu      sync.RWMutex
+}
+
+func NewConverter() *Converter {
+	return &Converter{
+		chars:   make(map[rune]string),
+		phrases: make(map[string]string),
+	}
+}
+
+// LoadChars loads a character mapping table
+func (c *Converter) LoadChars(file string) error {
+	return c.load(file, false)
+}
+
+// LoadPhrases loads a phrase mapping table
+func (c *Converter) LoadPhrases(file string) error {
+	return c.load(file, true)
+}
+
+// AddMapping adds the mapping of a character or a phrase
+func (c *Converter) AddMapping(source, target string) error {
+	if source == "" || target == "" {
+		return errors.New("the source or target is empty")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(source, target)
+	return nil
+}
+
+func (c *Converter) add(source, target string) {
+	n := utf8.RuneCountInString(source)
+	if n == 1 {
+		r, _ := utf8.DecodeRuneInString(source)
+		c.chars[r] = target
+		return
+	}
+	c.phrases[source] = target
+	if n > c.maxLen {
+		c.maxLen = n
+	}
+}
+
+func (c *Converter) load(file string, phrase bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timeStart := time.Now()
+
+	f, err := os.Open(file)
+	if err != nil {
+		log.Println(err)
+		return errors.New("unable to load the conversion table:" + filepath.Base(file))
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	itemCount := 0
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			break
+		}
+
+		elem := strings.Fields(line)
+		if len(elem) < 2 || strings.HasPrefix(elem[0], "#") ||
+			(!phrase && utf8.RuneCountInString(elem[0]) != 1) {
+			if err == io.EOF {
+				break
+			}
+			continue
+		}
+
+		itemCount++
+		c.add(elem[0], elem[1])
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	log.Printf("%v items are loaded in conversion table "+filepath.Base(file)+", and take %v\n",
+		itemCount, time.Now().Sub(timeStart))
+	return nil
+}
+
+// Convert converts the text
+func (c *Converter) Convert(s string) string {
+	return c.ConvertMap(s).Text
+}
+
+// Convert a character, the character itself if it is not in the table
+func (c *Converter) convertChar(r rune) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if target, ok := c.chars[r]; ok {
+		return target
+	}
+	return string(r)
+}
+
+// ConvertMap converts the text, the returned map keeps the offsets of the converted
+// text in the original text. The characters of a phrase converted to one of the same
+// length keep their own offsets.
+func (c *Converter) ConvertMap(s string) *TextMap {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	m := newTextMap(s)
+	offsets := make([]int, 0, len(s)+1)
+	for i := range s {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(s))
+	n := len(offsets) - 1
+
+	for i := 0; i < n; {
+		// the longest phrase first
+		l := c.maxLen
+		if l > n-i {
+			l = n - i
+		}
+		for ; l > 1; l-- {
+			target, ok := c.phrases[s[offsets[i]:offsets[i+l]]]
+			if !ok {
+				continue
+			}
+			if utf8.RuneCountInString(target) == l {
+				k := i
+				for _, r := range target {
+					m.write(string(r), offsets[k], offsets[k+1])
+					k++
+				}
+			} else {
+				m.write(target, offsets[i], offsets[i+l])
+			}
+			break
+		}
+		if l > 1 {
+			i += l
+			continue
+		}
+
+		r, _ := utf8.DecodeRuneInString(s[offsets[i]:])
+		if target, ok := c.chars[r]; ok {
+			m.write(target, offsets[i], offsets[i+1])
+		} else {
+			m.copyText(s[offsets[i]:offsets[i+1]], offsets[i])
+		}
+		i++
+	}
+	return m.done()
+}
+
+// InitConverter loads the conversion tables in the dictionary directory, a missing
+// table leaves the conversion in its direction empty.
+func InitConverter() {
+	tables := []struct {
+		converter *Converter
+		file      string
+		phrase    bool
+	}{
+		{t2sConverter, T2SCharsFile, false},
+		{t2sConverter, T2SPhrasesFile, true},
+		{s2tConverter, S2TCharsFile, false},
+		{s2tConverter, S2TPhrasesFile, true},
+	}
+	for _, table := range tables {
+		file, err := GetDictFile(table.file)
+		if err != nil {
+			continue
+		}
+		if err = table.converter.load(file, table.phrase); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// GetT2SConverter returns the traditional to simplified converter
+func GetT2SConverter() *Converter {
+	return t2sConverter
+}
+
+// GetS2TConverter returns the simplified to traditional converter
+func GetS2TConverter() *Converter {
+	return s2tConverter
+}
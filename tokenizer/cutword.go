@@ -4,123 +4,194 @@
 
 package tokenizer
 
+import (
+	"regexp"
+	"strings"
+)
+
 func CutFullW(s string, words *[]string) {
-	bufEnglish := ""
+	defaultSegmenter.CutFullW(s, words)
+}
+
+func (sg *Segmenter) CutFullW(s string, words *[]string) {
+	sg.cutFull(NewSentence(s), appendWord(words))
+}
+
+// Cut out all the dictionary words of the sentence, the single English characters
+// and the characters of the extra classes are joined together
+func (sg *Segmenter) cutFull(sentence *Sentence, emit wordEmitter) {
+	class := sg.GetCharClass()
+
+	buf := ""
+	bufKind := runNone
+	bufStart, bufEnd := 0, 0
 	pos := -1
 
-	sentence := NewSentence(s)
 	dag := sentence.GetDAG()
 	for k, listPos := range dag {
-		if len(bufEnglish) > 0 && !IsEnglishChars(sentence.GetChar(k)) {
-			*words = append(*words, bufEnglish)
-			bufEnglish = ""
+		if len(buf) > 0 && class.runKind(sentence.GetChar(k)) != bufKind {
+			emit(buf, bufStart, bufEnd, SourceEnglish, "")
+			buf = ""
 		}
 
 		if len(listPos) == 1 && k > pos {
 			word := sentence.GetWord(k, listPos[0]+1)
-			if IsEnglishChars(word) {
-				bufEnglish += word
+			if kind := class.runKind(word); kind != runNone {
+				if len(buf) == 0 {
+					bufStart, bufKind = k, kind
+				}
+				buf += word
+				bufEnd = listPos[0] + 1
 			}
-			if len(bufEnglish) == 0 {
-				*words = append(*words, word)
+			if len(buf) == 0 {
+				emit(word, k, listPos[0]+1, SourceDict, "")
 			}
 			pos = listPos[0]
 		} else {
 			for _, j := range listPos {
 				if j > k {
-					*words = append(*words, sentence.GetWord(k, j+1))
+					emit(sentence.GetWord(k, j+1), k, j+1, SourceDict, "")
 					pos = j
 				}
 			}
 		}
 	}
 
-	if len(bufEnglish) > 0 {
-		*words = append(*words, bufEnglish)
+	if len(buf) > 0 {
+		emit(buf, bufStart, bufEnd, SourceEnglish, "")
 	}
 }
 
 func CutAccurateW(s string, words *[]string) {
+	defaultSegmenter.CutAccurateW(s, words)
+}
+
+func (sg *Segmenter) CutAccurateW(s string, words *[]string) {
 	sentence := NewSentence(s)
-	route := sentence.CalcDAG()
-	dictionary := GetDictionary()
-	buf := ""
+	sg.cutAccurate(sentence, sg.calcRoute(sentence), appendWord(words))
+}
+
+// Cut the sentence along the route, the characters left single by the route are
+// passed to the recognizer.
+func (sg *Segmenter) cutAccurate(sentence *Sentence, route []NodeDAG, emit wordEmitter) {
+	bufStart := -1
 	for i := 0; i < sentence.Len(); {
 		y := route[i].Y + 1
-		leftWord := sentence.GetWord(i, y)
 		if y-i == 1 {
-			buf += leftWord
+			if bufStart < 0 {
+				bufStart = i
+			}
 			i = y
 			continue
 		}
 
-		if len(buf) > 0 {
-			if len([]rune(buf)) == 1 {
-				*words = append(*words, buf)
-			} else {
-				if !dictionary.Exist(buf) {
-					wordsRecognized := GetFinalSeg().Cut(buf)
-					for _, w := range wordsRecognized {
-						*words = append(*words, w)
-					}
-				} else {
-					for _, v := range buf {
-						*words = append(*words, string(v))
-					}
-				}
-			}
-			buf = ""
+		if bufStart >= 0 {
+			sg.cutBuf(sentence, bufStart, i, emit)
+			bufStart = -1
 		}
-		*words = append(*words, leftWord)
+		emit(sentence.GetWord(i, y), i, y, SourceDict, "")
 		i = y
 	}
 
-	if len(buf) > 0 {
-		if len([]rune(buf)) == 1 {
-			*words = append(*words, buf)
+	if bufStart >= 0 {
+		sg.cutBuf(sentence, bufStart, sentence.Len(), emit)
+	}
+}
+
+// Cut the characters which are not covered by dictionary words, the unknown words
+// in them are recognized by the recognizer of the segmenter.
+func (sg *Segmenter) cutBuf(sentence *Sentence, start, end int, emit wordEmitter) {
+	buf := sentence.GetWord(start, end)
+	if end-start == 1 {
+		emit(buf, start, end, SourceDict, "")
+		return
+	}
+
+	if GetDictionary().Exist(buf) {
+		for i := start; i < end; i++ {
+			emit(sentence.GetChar(i), i, i+1, SourceDict, "")
+		}
+		return
+	}
+
+	var wordsRecognized, states []string
+	recognizer := sg.GetRecognizer()
+	if fs, ok := recognizer.(*FinalSeg); ok {
+		wordsRecognized, states = fs.cutStates(buf, sg.GetCharClass())
+	} else if r, ok := recognizer.(StateRecognizer); ok {
+		wordsRecognized, states = r.CutStates(buf)
+	} else {
+		wordsRecognized = recognizer.Cut(buf)
+	}
+	// the words of a recognizer must rebuild the buffer, otherwise their offsets
+	// are wrong, the built-in HMM is used instead
+	if strings.Join(wordsRecognized, "") != buf || (states != nil && len(states) != len(wordsRecognized)) {
+		wordsRecognized, states = GetFinalSeg().cutStates(buf, sg.GetCharClass())
+	}
+
+	pos := start
+	for i, w := range wordsRecognized {
+		n := len([]rune(w))
+		if states == nil {
+			emit(w, pos, pos+n, SourceHMM, "")
+		} else if states[i] == "" {
+			emit(w, pos, pos+n, SourceEnglish, "")
 		} else {
-			if !dictionary.Exist(buf) {
-				wordsRecognized := GetFinalSeg().Cut(buf)
-				for _, w := range wordsRecognized {
-					*words = append(*words, w)
-				}
-			} else {
-				for _, v := range buf {
-					*words = append(*words, string(v))
-				}
-			}
+			emit(w, pos, pos+n, SourceHMM, states[i])
 		}
+		pos += n
 	}
 }
 
 func CutNoHMMW(s string, words *[]string) {
+	defaultSegmenter.CutNoHMMW(s, words)
+}
+
+func (sg *Segmenter) CutNoHMMW(s string, words *[]string) {
 	sentence := NewSentence(s)
-	route := sentence.CalcDAG()
+	sg.cutNoHMM(sentence, sg.calcRoute(sentence), appendWord(words))
+}
 
-	bufEnglish := ""
+// Cut the sentence along the route, the single English characters and the characters
+// of the extra classes are joined together
+func (sg *Segmenter) cutNoHMM(sentence *Sentence, route []NodeDAG, emit wordEmitter) {
+	class := sg.GetCharClass()
+	bufStart := -1
+	bufKind := runNone
 	for i := 0; i < sentence.Len(); {
 		y := route[i].Y + 1
 		leftWord := sentence.GetWord(i, y)
-		if IsEnglishChars(leftWord) && len(leftWord) == 1 {
-			bufEnglish += leftWord
+		kind := runNone
+		if y-i == 1 {
+			kind = class.runKind(leftWord)
+		}
+		if bufStart >= 0 && kind != bufKind {
+			emit(sentence.GetWord(bufStart, i), bufStart, i, SourceEnglish, "")
+			bufStart = -1
+		}
+		if kind != runNone {
+			if bufStart < 0 {
+				bufStart, bufKind = i, kind
+			}
 			i = y
 			continue
 		}
 
-		if len(bufEnglish) > 0 {
-			*words = append(*words, bufEnglish)
-			bufEnglish = ""
-		}
-		*words = append(*words, leftWord)
+		emit(leftWord, i, y, SourceDict, "")
 		i = y
 	}
 
-	if len(bufEnglish) > 0 {
-		*words = append(*words, bufEnglish)
+	if bufStart >= 0 {
+		emit(sentence.GetWord(bufStart, sentence.Len()), bufStart, sentence.Len(), SourceEnglish, "")
 	}
 }
 
 func CutSymbolW(s string, words *[]string) {
+	cutSymbol(s, reDelimiter, words)
+}
+
+// Cut the symbols at the delimiters, the delimiters are words too
+func cutSymbol(s string, reDelimiter *regexp.Regexp, words *[]string) {
 	n := len(s)
 	if n == 0 {
 		return
@@ -171,4 +242,8 @@ func Init(dictPath string) {
 	InitDictionary()
 	InitTFIDF()
 	InitFSToken()
+	InitConverter()
+	InitGazetteer()
+	InitSynonyms()
+	InitSensitiveWords()
 }
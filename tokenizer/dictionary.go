@@ -18,13 +18,16 @@ import (
 )
 
 var dictionary = &Dictionary{
-	dict: make(map[string]int),
+	dict:  make(map[string]int),
+	props: make(map[string]string),
 }
 
 type Dictionary struct {
-	dict map[string]int
-	mu   sync.RWMutex
-	tf   int // total freq
+	dict  map[string]int
+	props map[string]string // part of speech of the words
+	mu    sync.RWMutex
+	tf    int // total freq
+	ml    int // rune length of the longest word
 }
 
 func (d *Dictionary) Exist(word string) bool {
@@ -41,12 +44,26 @@ func (d *Dictionary) GetWord(word string) (int, bool) {
 	return v, ok
 }
 
+// GetProp returns the part of speech of the word, empty if the word is unknown
+func (d *Dictionary) GetProp(word string) string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.props[strings.ToLower(word)]
+}
+
 func (d *Dictionary) GetTotalFreq() float64 {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 	return float64(d.tf)
 }
 
+// GetMaxWordLen returns the rune length of the longest word
+func (d *Dictionary) GetMaxWordLen() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.ml
+}
+
 func (d *Dictionary) AddWord(word string, freq int, prop string) (exist bool, err error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -97,7 +114,11 @@ func (d *Dictionary) AddWord(word string, freq int, prop string) (exist bool, er
 	}
 
 	d.dict[strings.ToLower(word)] = freq
+	d.props[strings.ToLower(word)] = prop
 	d.tf += freq
+	if n := len([]rune(word)); n > d.ml {
+		d.ml = n
+	}
 	return
 }
 
@@ -139,8 +160,12 @@ func (d *Dictionary) load(fileDict string) error {
 		}
 		d.tf += nFreq
 		d.dict[strings.ToLower(elem[0])] = nFreq
+		d.props[strings.ToLower(elem[0])] = elem[2]
 
 		runeWord := []rune(elem[0])
+		if len(runeWord) > d.ml {
+			d.ml = len(runeWord)
+		}
 		for i := range runeWord {
 			s := strings.ToLower(string(runeWord[:i+1]))
 			if _, ok := d.dict[s]; !ok {
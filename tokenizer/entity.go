@@ -0,0 +1,420 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	EntityPerson       = "PERSON"       // names of people
+	EntityLocation     = "LOCATION"     // names of places
+	EntityOrganization = "ORGANIZATION" // names of organizations
+)
+
+// entity types of the parts of speech of the dictionary
+var propEntities = map[string]string{
+	"nr":   EntityPerson,
+	"nrfg": EntityPerson,
+	"nrt":  EntityPerson,
+	"ns":   EntityLocation,
+	"nsf":  EntityLocation,
+	"nt":   EntityOrganization,
+}
+
+// common Chinese surnames, the unknown words starting with one of them are names
+var surnames = makeSet(strings.Fields(`
+	王 李 张 刘 陈 杨 黄 赵 吴 周 徐 孙 马 朱 胡 郭 何 高 林 罗 郑 梁 谢 宋 唐 许 韩 冯 邓 曹
+	彭 曾 肖 田 董 袁 潘 于 蒋 蔡 余 杜 叶 程 苏 魏 吕 丁 任 沈 姚 卢 姜 崔 钟 谭 陆 汪 范 金
+	石 廖 贾 夏 韦 付 方 白 邹 孟 熊 秦 邱 江 尹 薛 闫 段 雷 侯 龙 史 陶 黎 贺 顾 毛 郝 龚 邵
+	万 钱 严 覃 武 戴 莫 孔 向 汤 欧阳 司马 上官 诸葛 东方 皇甫 令狐 慕容 端木 公孙`))
+
+// suffixes making a place of the place in front of them, such as 海淀区
+var locationSuffixes = makeSet(strings.Fields(`省 市 县 区 镇 乡 村 州 路 街 道 港 湾 岛 山 河 湖`))
+
+// suffixes making an organization of the names in front of them, such as 北京大学
+var organizationSuffixes = makeSet(strings.Fields(`
+	公司 集团 银行 大学 学院 学校 中学 小学 医院 研究所 研究院 委员会 协会 学会 基金会
+	政府 法院 检察院 出版社 报社 电视台 俱乐部 中心 局 厅 部 署 所`))
+
+func makeSet(items []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		set[item] = struct{}{}
+	}
+	return set
+}
+
+var gazetteer = NewGazetteer()
+
+// Gazetteer is a list of known entities, such as names of people, places and
+// organizations. The entity file has an entity per line: "name<SPACE>type", the type
+// is PERSON, LOCATION or ORGANIZATION, one of the parts of speech nr, ns and nt, or
+// any other type of the user. Names with spaces are not supported.
+type Gazetteer struct {
+	entries map[string]string
+	maxLen  int // maximal rune length of the names
+	mu      sync.RWMutex
+}
+
+func NewGazetteer() *Gazetteer {
+	return &Gazetteer{
+		entries: make(map[string]string),
+	}
+}
+
+// Load loads an entity file
+func (z *Gazetteer) Load(file string) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	timeStart := time.Now()
+
+	f, err := os.Open(file)
+	if err != nil {
+		log.Println(err)
+		return errors.New("unable to load the entity file:" + filepath.Base(file))
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	itemCount := 0
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			break
+		}
+
+		elem := strings.Fields(line)
+		if len(elem) != 2 || strings.HasPrefix(elem[0], "#") {
+			if err == io.EOF {
+				break
+			}
+			continue
+		}
+
+		itemCount++
+		z.add(elem[0], entityType(elem[1]))
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	log.Printf("%v entities are loaded in entity file "+filepath.Base(file)+", and take %v\n",
+		itemCount, time.Now().Sub(timeStart))
+	return nil
+}
+
+// AddEntity adds an entity of the type, the type may be a part of speech such as nr
+func (z *Gazetteer) AddEntity(name, typ string) error {
+	name = strings.TrimSpace(name)
+	typ = strings.TrimSpace(typ)
+	if name == "" || typ == "" {
+		return errors.New("the name or type of the entity is empty")
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.add(name, entityType(typ))
+	return nil
+}
+
+func (z *Gazetteer) add(name, typ string) {
+	z.entries[strings.ToLower(name)] = typ
+	if n := utf8.RuneCountInString(name); n > z.maxLen {
+		z.maxLen = n
+	}
+}
+
+// Lookup returns the type of the entity, false if it is unknown
+func (z *Gazetteer) Lookup(name string) (string, bool) {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	typ, ok := z.entries[strings.ToLower(name)]
+	return typ, ok
+}
+
+// FindSpans returns the spans of the entities in the text, the longest one wins. A
+// name starting or ending with a letter or digit is not matched inside a word.
+func (z *Gazetteer) FindSpans(s string) []Span {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+
+	if len(z.entries) == 0 {
+		return nil
+	}
+
+	offsets := make([]int, 0, len(s)+1)
+	for i := range s {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(s))
+	n := len(offsets) - 1
+
+	var spans []Span
+	for i := 0; i < n; {
+		l := z.maxLen
+		if l > n-i {
+			l = n - i
+		}
+		for ; l > 0; l-- {
+			start, end := offsets[i], offsets[i+l]
+			typ, ok := z.entries[strings.ToLower(s[start:end])]
+			if ok && isWordBoundary(s, start) && isWordBoundary(s, end) {
+				spans = append(spans, Span{Start: start, End: end, Type: typ})
+				break
+			}
+		}
+		if l > 0 {
+			i += l
+			continue
+		}
+		i++
+	}
+	return spans
+}
+
+// Whether the offset is not between two letters or digits
+func isWordBoundary(s string, offset int) bool {
+	if offset == 0 || offset == len(s) {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(s[:offset])
+	after, _ := utf8.DecodeRuneInString(s[offset:])
+	return !(isAlnum(before) && isAlnum(after))
+}
+
+func isAlnum(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// Turn a part of speech into its entity type, the other types are kept upper-cased
+func entityType(typ string) string {
+	if v, ok := propEntities[strings.ToLower(typ)]; ok {
+		return v
+	}
+	return strings.ToUpper(typ)
+}
+
+// InitGazetteer loads the user-defined entity file in the dictionary directory
+func InitGazetteer() {
+	file, err := GetDictFile(EntityUserFile)
+	if err != nil {
+		return
+	}
+	if err = gazetteer.Load(file); err != nil {
+		log.Println(err)
+	}
+}
+
+func GetGazetteer() *Gazetteer {
+	return gazetteer
+}
+
+// MergeEntities returns the names of people, places and organizations in the tokens
+// of a sentence cut in the accurate mode. The tokens labelled by the gazetteer keep
+// their types, the others are typed by their parts of speech in the dictionary. The
+// unknown words starting with a surname, which the HMM recognizes, are names of
+// people unless they end with the suffix of a place or an organization, and so is a
+// surname followed by an unknown word or a given name. Adjacent places are
+// merged, and so is a place with a suffix such as 市. A place or an organization
+// followed by nouns ending with a suffix such as 大学 is an organization. The tokens
+// of the other types, such as the numeric entities and the matches of the patterns,
+// are kept as they are.
+func MergeEntities(tokens []Token) []Token {
+	types := make([]string, len(tokens))
+	for i, token := range tokens {
+		types[i] = tokenEntityType(token)
+	}
+
+	entities := make([]Token, 0)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		typ := types[i]
+
+		if typ == "" && isSurname(token.Text) && i+1 < len(tokens) && adjacent(tokens[i], tokens[i+1]) &&
+			(types[i+1] == "" && isGivenName(tokens[i+1].Text) ||
+				types[i+1] == EntityPerson && givenNameLen(tokens[i+1].Text) <= 2) {
+			// a surname followed by a given name, which may be a name of the
+			// dictionary, such as 王 小明
+			token = mergeTokens(token, tokens[i+1])
+			typ = EntityPerson
+			i++
+		}
+		if typ == "" {
+			continue
+		}
+		if typ == EntityPerson && i+1 < len(tokens) && types[i+1] == "" && adjacent(token, tokens[i+1]) &&
+			utf8.RuneCountInString(tokens[i+1].Text) == 1 && isGivenName(tokens[i+1].Text) &&
+			givenNameLen(token.Text) == 1 {
+			// the HMM may leave the last character of a name alone, such as 欧阳娜 娜
+			token = mergeTokens(token, tokens[i+1])
+			i++
+		}
+		if typ == EntityLocation {
+			for i+1 < len(tokens) && adjacent(token, tokens[i+1]) && isLocationPart(tokens[i+1], types[i+1]) {
+				token = mergeTokens(token, tokens[i+1])
+				i++
+			}
+		}
+		if typ == EntityLocation || typ == EntityOrganization {
+			if j := organizationEnd(tokens, types, i); j > i {
+				for k := i + 1; k <= j; k++ {
+					token = mergeTokens(token, tokens[k])
+				}
+				typ = EntityOrganization
+				i = j
+			}
+		}
+
+		token.Type = typ
+		entities = append(entities, token)
+	}
+	return entities
+}
+
+// Whether the token following a place is a part of it: another place, a suffix such
+// as 市, or an unknown word ending with a suffix such as 海淀区
+func isLocationPart(token Token, typ string) bool {
+	if typ == EntityLocation {
+		return true
+	}
+	if typ != "" {
+		return false
+	}
+	if _, ok := locationSuffixes[token.Text]; ok {
+		return true
+	}
+	last, _ := utf8.DecodeLastRuneInString(token.Text)
+	_, ok := locationSuffixes[string(last)]
+	return ok && !isKnownWord(token.Text) && IsChineseChars(token.Text)
+}
+
+// Return the index of the organization suffix ending the organization starting at
+// the token i, i if there is none. Up to two nouns may be between them.
+func organizationEnd(tokens []Token, types []string, i int) int {
+	for j := i + 1; j < len(tokens) && j <= i+3; j++ {
+		if !adjacent(tokens[j-1], tokens[j]) {
+			break
+		}
+		if _, ok := organizationSuffixes[tokens[j].Text]; ok {
+			return j
+		}
+		if types[j] != "" {
+			break
+		}
+		if prop := dictionary.GetProp(tokens[j].Text); prop != "" && !strings.HasPrefix(prop, "n") {
+			break
+		}
+	}
+	return i
+}
+
+func tokenEntityType(token Token) string {
+	if token.Type != "" {
+		return token.Type
+	}
+
+	if typ, ok := propEntities[dictionary.GetProp(token.Text)]; ok {
+		if typ == EntityPerson && utf8.RuneCountInString(token.Text) < 2 {
+			// a surname alone
+			return ""
+		}
+		return typ
+	}
+
+	// an unknown name recognized by the HMM
+	n := utf8.RuneCountInString(token.Text)
+	if n >= 2 && n <= 4 && !isKnownWord(token.Text) && !hasEntitySuffix(token.Text) {
+		if l := surnameLen(token.Text); l > 0 && n-l <= 2 && isGivenName(token.Text[l:]) {
+			return EntityPerson
+		}
+	}
+	return ""
+}
+
+// Whether the word ends with the suffix of a place or an organization, such as 武汉市,
+// which is not a name even if it starts with a surname
+func hasEntitySuffix(s string) bool {
+	last, _ := utf8.DecodeLastRuneInString(s)
+	if _, ok := locationSuffixes[string(last)]; ok {
+		return true
+	}
+	for suffix := range organizationSuffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the byte length of the surname the name starts with, 0 if there is none
+func surnameLen(s string) int {
+	for _, n := range []int{2, 1} {
+		i := 0
+		for k := 0; k < n && i < len(s); k++ {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+		}
+		if i < len(s) && isSurname(s[:i]) {
+			return i
+		}
+	}
+	return 0
+}
+
+// Return the rune length of the given name of the name
+func givenNameLen(s string) int {
+	return utf8.RuneCountInString(s[surnameLen(s):])
+}
+
+func isSurname(s string) bool {
+	_, ok := surnames[s]
+	return ok
+}
+
+// Whether the word may be the given name following a surname. The given names are
+// one or two Chinese characters, none of which is a function word or a verb of the
+// dictionary, such as 说 of 王说.
+func isGivenName(s string) bool {
+	n := utf8.RuneCountInString(s)
+	if n < 1 || n > 2 || (n > 1 && isKnownWord(s)) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.Is(unicode.Han, r) {
+			return false
+		}
+		if prop := dictionary.GetProp(string(r)); prop != "" && strings.ContainsRune("vpcudrmqf", rune(prop[0])) {
+			return false
+		}
+	}
+	return true
+}
+
+func isKnownWord(s string) bool {
+	freq, ok := dictionary.GetWord(s)
+	return ok && freq > 0
+}
+
+func adjacent(a, b Token) bool {
+	return a.End == b.Start
+}
+
+func mergeTokens(a, b Token) Token {
+	return Token{Text: a.Text + b.Text, Start: a.Start, End: b.End, Type: a.Type}
+}
@@ -0,0 +1,60 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+// Evaluation compares segmentations with the gold standard ones, a word counts as
+// correct when the same span of the sentence is a word in both.
+type Evaluation struct {
+	Gold      int     `json:"gold"`      // count of words in the gold standard
+	Predicted int     `json:"predicted"` // count of words in the segmentations
+	Correct   int     `json:"correct"`   // count of correct words
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+// Evaluate computes the precision, recall and F1 of the predicted segmentations,
+// gold[i] and predicted[i] are the words of the same sentence.
+func Evaluate(gold, predicted [][]string) Evaluation {
+	e := Evaluation{}
+	for i := range gold {
+		goldSpans := wordSpans(gold[i])
+		e.Gold += len(goldSpans)
+		if i >= len(predicted) {
+			continue
+		}
+
+		predictedSpans := wordSpans(predicted[i])
+		e.Predicted += len(predictedSpans)
+		for span := range predictedSpans {
+			if _, ok := goldSpans[span]; ok {
+				e.Correct++
+			}
+		}
+	}
+
+	if e.Predicted > 0 {
+		e.Precision = float64(e.Correct) / float64(e.Predicted)
+	}
+	if e.Gold > 0 {
+		e.Recall = float64(e.Correct) / float64(e.Gold)
+	}
+	if e.Precision+e.Recall > 0 {
+		e.F1 = 2 * e.Precision * e.Recall / (e.Precision + e.Recall)
+	}
+	return e
+}
+
+// The rune spans of the words in the sentence
+func wordSpans(words []string) map[[2]int]struct{} {
+	spans := make(map[[2]int]struct{}, len(words))
+	pos := 0
+	for _, word := range words {
+		n := len([]rune(word))
+		spans[[2]int{pos, pos + n}] = struct{}{}
+		pos += n
+	}
+	return spans
+}
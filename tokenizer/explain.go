@@ -0,0 +1,102 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import "math"
+
+const (
+	SourceDict    = "dict"    // word on the dictionary route
+	SourceHMM     = "hmm"     // word recognized by the recognizer, the HMM by default
+	SourceEnglish = "english" // English letters and digits, or an extra class, joined together
+	SourceSymbol  = "symbol"  // symbols and delimiters cut by CutSymbolW
+	SourceSpan    = "span"    // protected span, such as a numeric entity or a match of a pattern
+	SourceMixed   = "mixed"   // run of Latin letters and digits cut by the mixed rule
+)
+
+// wordEmitter receives the words cut from a sentence in order, start and end are
+// the rune offsets of the word in the sentence.
+type wordEmitter func(word string, start, end int, source, states string)
+
+func appendWord(words *[]string) wordEmitter {
+	return func(word string, _, _ int, _, _ string) {
+		*words = append(*words, word)
+	}
+}
+
+// ExplainToken is a word with the reason why it is cut out.
+type ExplainToken struct {
+	Word       string  `json:"word"`
+	Start      int     `json:"start"`            // byte offset of the word in the text
+	End        int     `json:"end"`              // byte offset of the end of the word
+	Type       string  `json:"type,omitempty"`   // type of a protected span
+	Source     string  `json:"source"`           // one of the Source constants
+	Freq       int     `json:"freq"`             // frequency in the dictionary
+	Score      float64 `json:"score"`            // log probability of the word in the unigram model
+	RouteScore float64 `json:"route_score"`      // score of the best route from the word to the end of the text
+	States     string  `json:"states,omitempty"` // HMM states of the recognized words
+}
+
+// ExplainW cuts the text the same way as CutAccurateW, or CutNoHMMW if hmm is
+// false, and explains every word.
+func (sg *Segmenter) ExplainW(s string, hmm bool, tokens *[]ExplainToken) {
+	sg.ExplainT(s, 0, hmm, tokens)
+}
+
+// ExplainT explains the words the same way as ExplainW, the words have byte offsets
+// from base.
+func (sg *Segmenter) ExplainT(s string, base int, hmm bool, tokens *[]ExplainToken) {
+	dictionary := GetDictionary()
+	logTotal := math.Log(dictionary.GetTotalFreq())
+
+	offsets := make([]int, 0, len(s)+1)
+	for i := range s {
+		offsets = append(offsets, base+i)
+	}
+	offsets = append(offsets, base+len(s))
+
+	sentence := NewSentence(s)
+	route := sg.calcRoute(sentence)
+	emit := func(word string, start, end int, source, states string) {
+		freq, _ := dictionary.GetWord(word)
+		if freq < 0 {
+			freq = 0
+		}
+		score := -logTotal
+		if freq > 0 {
+			score = math.Log(float64(freq)) - logTotal
+		}
+		*tokens = append(*tokens, ExplainToken{
+			Word:       word,
+			Start:      offsets[start],
+			End:        offsets[end],
+			Source:     source,
+			Freq:       freq,
+			Score:      score,
+			RouteScore: route[start].X,
+			States:     states,
+		})
+	}
+
+	if hmm {
+		sg.cutAccurate(sentence, route, emit)
+	} else {
+		sg.cutNoHMM(sentence, route, emit)
+	}
+}
+
+// ExplainSymbolW cuts the symbols the same way as CutSymbolW with the delimiters of
+// the segmenter, and explains every symbol.
+func (sg *Segmenter) ExplainSymbolW(s string, tokens *[]ExplainToken) {
+	words := make([]Token, 0, DefaultWordsLen)
+	sg.CutSymbolT(s, 0, &words)
+	for _, word := range words {
+		*tokens = append(*tokens, ExplainToken{
+			Word:   word.Text,
+			Start:  word.Start,
+			End:    word.End,
+			Source: SourceSymbol,
+		})
+	}
+}
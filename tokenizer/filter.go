@@ -0,0 +1,64 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenFilter drops the tokens which callers of Cut usually do not want
+type TokenFilter struct {
+	StopWords   bool `json:"stop_words"`   // drop the stop words
+	Punctuation bool `json:"punctuation"`  // drop the tokens of punctuation, symbols and whitespace only
+	SingleChars bool `json:"single_chars"` // drop the tokens of a single character
+}
+
+// Enabled returns whether the filter drops any token
+func (f *TokenFilter) Enabled() bool {
+	return f != nil && (f.StopWords || f.Punctuation || f.SingleChars)
+}
+
+// Keep returns whether the word is kept by the filter
+func (f *TokenFilter) Keep(word string) bool {
+	if f == nil {
+		return true
+	}
+	if f.Punctuation && IsPunctuation(word) {
+		return false
+	}
+	if f.SingleChars && utf8.RuneCountInString(word) == 1 {
+		return false
+	}
+	if f.StopWords && tfIDF.ExistStopWord(word) {
+		return false
+	}
+	return true
+}
+
+// FilterTokens returns the tokens kept by the filter, the tokens are filtered in place
+func (f *TokenFilter) FilterTokens(tokens []Token) []Token {
+	if !f.Enabled() {
+		return tokens
+	}
+	kept := tokens[:0]
+	for _, token := range tokens {
+		if f.Keep(token.Text) {
+			kept = append(kept, token)
+		}
+	}
+	return kept
+}
+
+// IsPunctuation returns whether the word is made of punctuation, symbols and
+// whitespace only, such as the words of CutSymbolW
+func IsPunctuation(word string) bool {
+	for _, r := range word {
+		if !unicode.IsPunct(r) && !unicode.IsSymbol(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return word != ""
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"log"
+	"strings"
 	"sync"
 )
 
@@ -65,27 +66,45 @@ type FinalSeg struct {
 }
 
 func (fs *FinalSeg) Cut(sentence string) []string {
+	words, _ := fs.CutStates(sentence)
+	return words
+}
+
+// CutStates cuts the sentence the same way as Cut, and also returns the HMM states
+// of every word, such as "BME". The words which are not cut by the HMM have no states.
+func (fs *FinalSeg) CutStates(sentence string) ([]string, []string) {
+	return fs.cutStates(sentence, defaultCharClass)
+}
+
+// Cut the Chinese characters of the classification by the HMM
+func (fs *FinalSeg) cutStates(sentence string, class *CharClass) ([]string, []string) {
 	wordsRet := make([]string, 0, DefaultWordsLen)
+	statesRet := make([]string, 0, DefaultWordsLen)
 
-	segments := SplitChineseSeg(sentence)
+	segments := class.SplitChineseSeg(sentence)
 	for _, segment := range segments {
-		if IsChineseChars(segment) {
-			words := fs.cut(segment)
-			for _, v := range words {
+		if class.IsChineseChars(segment) {
+			words, states := fs.cut(segment)
+			for i, v := range words {
 				if fs.exist(v) {
 					for _, c := range v {
 						wordsRet = append(wordsRet, string(c))
+						statesRet = append(statesRet, "S")
 					}
 				} else {
 					wordsRet = append(wordsRet, v)
+					statesRet = append(statesRet, states[i])
 				}
 			}
 		} else {
-			words := SplitNumberSeg(segment)
-			wordsRet = append(wordsRet, words...)
+			words := class.SplitNumberSeg(segment)
+			for _, v := range words {
+				wordsRet = append(wordsRet, v)
+				statesRet = append(statesRet, "")
+			}
 		}
 	}
-	return wordsRet
+	return wordsRet, statesRet
 }
 
 func (fs *FinalSeg) getMatrixVal(name, key, word string) float64 {
@@ -141,7 +160,8 @@ func (fs *FinalSeg) viterbi(sentence string) []string {
 				}
 			}
 			v[i][y] = pb
-			pathNew[y] = append(path[st], y)
+			// copy the path, the paths of the states must not share the backing array
+			pathNew[y] = append(append(make([]string, 0, i+1), path[st]...), y)
 		}
 		path = pathNew
 	}
@@ -155,9 +175,10 @@ func (fs *FinalSeg) viterbi(sentence string) []string {
 	return path[state]
 }
 
-func (fs *FinalSeg) cut(sentence string) []string {
+func (fs *FinalSeg) cut(sentence string) ([]string, []string) {
 	rs := []rune(sentence)
 	wordsRet := make([]string, 0)
+	statesRet := make([]string, 0)
 	posList := fs.viterbi(sentence)
 
 	begin, next := 0, 0
@@ -167,17 +188,20 @@ func (fs *FinalSeg) cut(sentence string) []string {
 			begin = i
 		} else if pos == "E" {
 			wordsRet = append(wordsRet, string(rs[begin:i+1]))
+			statesRet = append(statesRet, strings.Join(posList[begin:i+1], ""))
 			next = i + 1
 		} else if pos == "S" {
 			wordsRet = append(wordsRet, string(word))
+			statesRet = append(statesRet, pos)
 			next = i + 1
 		}
 	}
 	if next < len(rs) {
 		wordsRet = append(wordsRet, string(rs[next:]))
+		statesRet = append(statesRet, strings.Join(posList[next:], ""))
 	}
 
-	return wordsRet
+	return wordsRet, statesRet
 }
 
 func (fs *FinalSeg) exist(word string) bool {
@@ -0,0 +1,171 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LatticeNode is a word candidate of a lattice. Start and End are rune offsets in
+// the text, ByteStart and ByteEnd are byte offsets.
+type LatticeNode struct {
+	Word      string `json:"word"`
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	ByteStart int    `json:"byte_start"`
+	ByteEnd   int    `json:"byte_end"`
+	Freq      int    `json:"freq"` // frequency in the dictionary, 0 if not a dictionary word
+	Prop      string `json:"prop"` // part of speech, x for symbols
+	Best      bool   `json:"best"` // whether the word is on the route the segmenter chooses
+}
+
+// Lattice holds all the word candidates of a text, the text segments contribute
+// the words of their DAG, and the symbol segments the symbols cut by CutSymbolW.
+type Lattice struct {
+	Text  string        `json:"text"`
+	Nodes []LatticeNode `json:"nodes"`
+}
+
+// BuildLattice builds the word lattice of the text, the best route is marked with
+// the model of the segmenter.
+func (sg *Segmenter) BuildLattice(s string) *Lattice {
+	lattice := &Lattice{
+		Text:  s,
+		Nodes: make([]LatticeNode, 0, DefaultWordsLen),
+	}
+	dictionary := GetDictionary()
+
+	class := sg.GetCharClass()
+	byteStart, runeStart := 0, 0
+	for _, segment := range class.SplitTextSeg(s) {
+		if strings.Trim(segment, " ") != "" {
+			if class.IsTextChars(segment) {
+				sg.buildTextLattice(segment, runeStart, byteStart, lattice)
+			} else {
+				symbols := make([]string, 0, DefaultWordsLen)
+				class.CutSymbolW(segment, &symbols)
+				start, end := runeStart, byteStart
+				for _, symbol := range symbols {
+					n := utf8.RuneCountInString(symbol)
+					lattice.Nodes = append(lattice.Nodes, LatticeNode{
+						Word:      symbol,
+						Start:     start,
+						End:       start + n,
+						ByteStart: end,
+						ByteEnd:   end + len(symbol),
+						Prop:      "x",
+						Best:      true,
+					})
+					start += n
+					end += len(symbol)
+				}
+			}
+		}
+		byteStart += len(segment)
+		runeStart += utf8.RuneCountInString(segment)
+	}
+
+	for i := range lattice.Nodes {
+		node := &lattice.Nodes[i]
+		if node.Prop == "" {
+			if freq, ok := dictionary.GetWord(node.Word); ok && freq > 0 {
+				node.Freq = freq
+				node.Prop = dictionary.GetProp(node.Word)
+			}
+		}
+	}
+	return lattice
+}
+
+func (sg *Segmenter) buildTextLattice(s string, runeStart, byteStart int, lattice *Lattice) {
+	sentence := NewSentence(s)
+	dag := sentence.GetDAG()
+	route := sg.calcRoute(sentence)
+
+	offsets := make([]int, 0, sentence.Len()+1)
+	for i := range s {
+		offsets = append(offsets, byteStart+i)
+	}
+	offsets = append(offsets, byteStart+len(s))
+
+	best := make(map[[2]int]struct{})
+	for i := 0; i < sentence.Len(); {
+		y := route[i].Y + 1
+		best[[2]int{i, y}] = struct{}{}
+		i = y
+	}
+
+	for k, ends := range dag {
+		for _, x := range ends {
+			_, ok := best[[2]int{k, x + 1}]
+			lattice.Nodes = append(lattice.Nodes, LatticeNode{
+				Word:      sentence.GetWord(k, x+1),
+				Start:     runeStart + k,
+				End:       runeStart + x + 1,
+				ByteStart: offsets[k],
+				ByteEnd:   offsets[x+1],
+				Best:      ok,
+			})
+		}
+	}
+}
+
+// DOT renders the lattice in the Graphviz DOT language. The positions between the
+// runes are the nodes, the words are the edges, and the best route is drawn in red.
+func (l *Lattice) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph lattice {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=circle, fontsize=10];\n")
+
+	positions := make(map[int]struct{})
+	for _, node := range l.Nodes {
+		positions[node.Start] = struct{}{}
+		positions[node.End] = struct{}{}
+	}
+	for i := 0; i <= utf8.RuneCountInString(l.Text); i++ {
+		if _, ok := positions[i]; ok {
+			b.WriteString("\t" + strconv.Itoa(i) + ";\n")
+		}
+	}
+
+	for _, node := range l.Nodes {
+		label := escapeDOT(node.Word)
+		if node.Freq > 0 {
+			label += "\\n" + strconv.Itoa(node.Freq) + " " + escapeDOT(node.Prop)
+		}
+		b.WriteString("\t" + strconv.Itoa(node.Start) + " -> " + strconv.Itoa(node.End) +
+			" [label=\"" + label + "\"")
+		if node.Best {
+			b.WriteString(", color=red, fontcolor=red, penwidth=2")
+		}
+		b.WriteString("];\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Escape a string for a quoted DOT label, the control characters are shown escaped
+func escapeDOT(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\', '"':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString("\\\\n")
+		case '\r':
+			b.WriteString("\\\\r")
+		case '\t':
+			b.WriteString("\\\\t")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,438 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// MarkupFormat is the markup language of a text
+type MarkupFormat int
+
+const (
+	MarkupPlain    MarkupFormat = iota // plain text, nothing is stripped
+	MarkupHTML                         // HTML or XML
+	MarkupMarkdown                     // Markdown, with inline HTML
+)
+
+// ParseMarkupFormat returns the format of the name, "html" or "markdown" (or "md").
+// Plain text is returned for any other name.
+func ParseMarkupFormat(name string) MarkupFormat {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "html", "htm", "xml":
+		return MarkupHTML
+	case "markdown", "md":
+		return MarkupMarkdown
+	default:
+		return MarkupPlain
+	}
+}
+
+var (
+	reEntity        = regexp.MustCompile(`^&(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	reTagName       = regexp.MustCompile(`^</?([A-Za-z][A-Za-z0-9-]*)`)
+	reMarkdownBlock = regexp.MustCompile(`^[ \t]{0,3}(#{1,6}[ \t]+|>[ \t]?|[-*+][ \t]+|[0-9]{1,9}[.)][ \t]+)`)
+	reMarkdownRule  = regexp.MustCompile(`^[ \t]{0,3}([-*_][ \t]*){3,}$`)
+	reMarkdownFence = regexp.MustCompile("^[ \t]{0,3}(```+|~~~+)")
+)
+
+// the content of these tags is not visible text
+var skippedTags = map[string]struct{}{
+	"script": {}, "style": {}, "pre": {}, "code": {}, "head": {}, "template": {}, "noscript": {},
+}
+
+// these tags do not break the words around them, the other tags do
+var inlineTags = map[string]struct{}{
+	"a": {}, "abbr": {}, "b": {}, "bdi": {}, "bdo": {}, "cite": {}, "del": {}, "dfn": {}, "em": {},
+	"font": {}, "i": {}, "ins": {}, "kbd": {}, "mark": {}, "q": {}, "s": {}, "samp": {}, "small": {},
+	"span": {}, "strike": {}, "strong": {}, "sub": {}, "sup": {}, "time": {}, "u": {}, "var": {},
+}
+
+// MarkupText is the visible text of a markup text, with the map from each byte of
+// the text back to the markup, which is the Source of the map
+type MarkupText = TextMap
+
+// ParseMarkup returns the visible text of the markup in the format. Tags, comments,
+// scripts and code blocks are skipped, entities are decoded, and the tags breaking
+// the text, such as <p> or a Markdown heading, are replaced with a space.
+func ParseMarkup(s string, format MarkupFormat) *MarkupText {
+	m := newTextMap(s)
+	switch format {
+	case MarkupHTML:
+		m.parseHTML(s, 0)
+	case MarkupMarkdown:
+		m.parseMarkdown(s)
+	default:
+		m.copyText(s, 0)
+	}
+	return m.done()
+}
+
+// ParseHTML returns the visible text of the HTML
+func ParseHTML(s string) *MarkupText {
+	return ParseMarkup(s, MarkupHTML)
+}
+
+// ParseMarkdown returns the visible text of the Markdown
+func ParseMarkdown(s string) *MarkupText {
+	return ParseMarkup(s, MarkupMarkdown)
+}
+
+// Append the text with the entities decoded, its offset in the markup is base
+func (m *TextMap) decodeText(s string, base int) {
+	pos := 0
+	for {
+		i := strings.IndexByte(s[pos:], '&')
+		if i < 0 {
+			break
+		}
+		i += pos
+		m.copyText(s[pos:i], base+pos)
+
+		loc := reEntity.FindStringIndex(s[i:])
+		if loc == nil {
+			m.copyText("&", base+i)
+			pos = i + 1
+			continue
+		}
+		entity := s[i : i+loc[1]]
+		text := html.UnescapeString(entity)
+		if text == entity {
+			m.copyText(entity, base+i)
+		} else {
+			// a non-breaking space is a space to the segmenter
+			m.write(strings.ReplaceAll(text, "\u00a0", " "), base+i, base+i+loc[1])
+		}
+		pos = i + loc[1]
+	}
+	m.copyText(s[pos:], base+pos)
+}
+
+// Parse the HTML, its offset in the markup is base
+func (m *TextMap) parseHTML(s string, base int) {
+	pos := 0
+	for pos < len(s) {
+		i := strings.IndexByte(s[pos:], '<')
+		if i < 0 {
+			break
+		}
+		i += pos
+		m.decodeText(s[pos:i], base+pos)
+
+		end := htmlTagEnd(s, i)
+		if end < 0 {
+			m.copyText("<", base+i)
+			pos = i + 1
+			continue
+		}
+
+		tag := s[i:end]
+		name := ""
+		if loc := reTagName.FindStringSubmatch(tag); loc != nil {
+			name = strings.ToLower(loc[1])
+		}
+		if _, ok := skippedTags[name]; ok && !strings.HasPrefix(tag, "</") && !strings.HasSuffix(tag, "/>") {
+			// skip the content to the closing tag
+			closing := indexFold(s[end:], "</"+name)
+			if closing < 0 {
+				end = len(s)
+			} else if closeEnd := htmlTagEnd(s, end+closing); closeEnd > 0 {
+				end = closeEnd
+			} else {
+				end = len(s)
+			}
+		}
+
+		if _, ok := inlineTags[name]; !ok {
+			m.writeSeparator(base+i, base+end)
+		}
+		pos = end
+	}
+	if pos < len(s) {
+		m.decodeText(s[pos:], base+pos)
+	}
+}
+
+// Return the end of the tag, comment or declaration starting at i, or -1 if there
+// is not one
+func htmlTagEnd(s string, i int) int {
+	rest := s[i:]
+	switch {
+	case strings.HasPrefix(rest, "<!--"):
+		if end := strings.Index(rest[4:], "-->"); end >= 0 {
+			return i + 4 + end + 3
+		}
+		return len(s)
+	case len(rest) < 2:
+		return -1
+	}
+
+	c := rest[1]
+	if !(c == '/' || c == '!' || c == '?' || (c|0x20 >= 'a' && c|0x20 <= 'z')) {
+		return -1
+	}
+
+	// the > in the quoted attribute values does not end the tag
+	quote := byte(0)
+	for j := 1; j < len(rest); j++ {
+		switch {
+		case quote != 0:
+			if rest[j] == quote {
+				quote = 0
+			}
+		case rest[j] == '"' || rest[j] == '\'':
+			quote = rest[j]
+		case rest[j] == '>':
+			return i + j + 1
+		case rest[j] == '<':
+			return -1
+		}
+	}
+	return -1
+}
+
+// Parse the Markdown line by line. The fenced code blocks and the indented ones,
+// which do not follow a paragraph or a list item, are skipped, and so are the markers
+// of the headings, quotes, list items and rules. The inline markup is parsed by
+// parseMarkdownInline.
+func (m *TextMap) parseMarkdown(s string) {
+	fence := ""
+	pos := 0
+	// whether an indented line may start a code block, and whether the lines are in a
+	// code block or in a list item
+	indentCode, inCode, inList := true, false, false
+	for pos < len(s) {
+		lineEnd := strings.IndexByte(s[pos:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(s)
+		} else {
+			lineEnd += pos + 1
+		}
+		line := strings.TrimRight(s[pos:lineEnd], "\r\n")
+
+		if fence != "" {
+			// inside a fenced code block
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence, indentCode = "", true
+			}
+			m.writeSeparator(pos, lineEnd)
+			pos = lineEnd
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			indentCode = !inList
+			m.copyText(s[pos:lineEnd], pos)
+			pos = lineEnd
+			continue
+		}
+		indented := strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+		if indented && (indentCode || inCode) {
+			inCode = true
+			m.writeSeparator(pos, lineEnd)
+			pos = lineEnd
+			continue
+		}
+		indentCode, inCode = false, false
+		if !indented {
+			inList = false
+		}
+
+		if loc := reMarkdownFence.FindStringSubmatch(line); loc != nil {
+			fence = loc[1]
+			m.writeSeparator(pos, lineEnd)
+			pos = lineEnd
+			continue
+		}
+		if reMarkdownRule.MatchString(line) {
+			indentCode = true
+			m.writeSeparator(pos, lineEnd)
+			pos = lineEnd
+			continue
+		}
+
+		start := pos
+		// the markers may be nested, such as a list item in a quote
+		for {
+			loc := reMarkdownBlock.FindStringIndex(s[start:lineEnd])
+			if loc == nil || loc[1] == 0 {
+				break
+			}
+			switch marker := strings.TrimSpace(s[start : start+loc[1]]); {
+			case marker[0] == '#':
+				// a heading is not a paragraph
+				indentCode = true
+			case marker != ">":
+				inList = true
+			}
+			m.writeSeparator(start, start+loc[1])
+			start += loc[1]
+		}
+		text := strings.TrimRight(s[start:lineEnd], "\r\n")
+		m.parseMarkdownInline(text, start)
+		m.copyText(s[start+len(text):lineEnd], start+len(text))
+		pos = lineEnd
+	}
+}
+
+// Parse the inline Markdown: code spans are skipped, the emphasis markers are
+// dropped, the links and images are replaced with their texts, and the inline
+// HTML is parsed as HTML.
+func (m *TextMap) parseMarkdownInline(s string, base int) {
+	pos, textStart := 0, 0
+	opened := 0 // the open runs of asterisks
+	flush := func(end int) {
+		if end > textStart {
+			m.parseHTML(s[textStart:end], base+textStart)
+		}
+	}
+
+	for pos < len(s) {
+		switch c := s[pos]; {
+		case c == '\\' && pos+1 < len(s) && strings.IndexByte("\\`*_{}[]()#+-.!~<>&", s[pos+1]) >= 0:
+			flush(pos)
+			m.copyText(s[pos+1:pos+2], base+pos+1)
+			pos += 2
+			textStart = pos
+
+		case c == '`':
+			n := 1
+			for pos+n < len(s) && s[pos+n] == '`' {
+				n++
+			}
+			ticks := s[pos : pos+n]
+			end := strings.Index(s[pos+n:], ticks)
+			if end < 0 {
+				pos += n
+				continue
+			}
+			flush(pos)
+			end = pos + n + end + n
+			m.writeSeparator(base+pos, base+end)
+			pos, textStart = end, end
+
+		case c == '*' || c == '~' || (c == '_' && isMarkdownBoundary(s, pos)):
+			n := 1
+			for pos+n < len(s) && s[pos+n] == c {
+				n++
+			}
+			if c == '~' && n < 2 {
+				pos++
+				continue
+			}
+			if c == '*' {
+				switch {
+				case opened > 0 && isMarkdownFlanking(s, pos, n, false):
+					opened--
+				case isMarkdownFlanking(s, pos, n, true) && markdownCloser(s, pos+n):
+					opened++
+				default:
+					// not emphasis, such as 2 * 3
+					pos += n
+					continue
+				}
+			}
+			flush(pos)
+			pos += n
+			textStart = pos
+
+		case c == '!' && pos+1 < len(s) && s[pos+1] == '[', c == '[':
+			open := pos
+			if c == '!' {
+				open++
+			}
+			label, end := markdownLink(s, open)
+			if end < 0 {
+				pos++
+				continue
+			}
+			flush(pos)
+			m.parseMarkdownInline(s[open+1:open+1+len(label)], base+open+1)
+			pos, textStart = end, end
+
+		default:
+			pos++
+		}
+	}
+	flush(len(s))
+}
+
+// Whether the underscore at i is next to a space or a symbol, the underscores in
+// the middle of a word, such as snake_case, are not emphasis markers
+func isMarkdownBoundary(s string, i int) bool {
+	j := i
+	for j < len(s) && s[j] == '_' {
+		j++
+	}
+	isWordByte := func(c byte) bool {
+		return c >= 0x80 || c == '_' || (c|0x20 >= 'a' && c|0x20 <= 'z') || (c >= '0' && c <= '9')
+	}
+	before := i == 0 || !isWordByte(s[i-1])
+	after := j == len(s) || !isWordByte(s[j])
+	return before || after
+}
+
+// Whether the run of n markers at i opens emphasis, it is followed by a non-space
+// character, or closes it, it follows a non-space character
+func isMarkdownFlanking(s string, i, n int, opening bool) bool {
+	if opening {
+		return i+n < len(s) && !isMarkdownSpace(s[i+n])
+	}
+	return i > 0 && !isMarkdownSpace(s[i-1])
+}
+
+// Whether a run of asterisks after i closes emphasis
+func markdownCloser(s string, i int) bool {
+	for j := i; j < len(s); j++ {
+		if s[j] == '*' && isMarkdownFlanking(s, j, 1, false) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMarkdownSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// Return the label of the link [label](url) or [label][ref] starting at i, and the
+// end of the link, or -1 if there is not one
+func markdownLink(s string, i int) (string, int) {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			label := s[i+1 : j]
+			if j+1 < len(s) && (s[j+1] == '(' || s[j+1] == '[') {
+				closing := byte(')')
+				if s[j+1] == '[' {
+					closing = ']'
+				}
+				if end := strings.IndexByte(s[j+2:], closing); end >= 0 {
+					return label, j + 2 + end + 1
+				}
+			}
+			return "", -1
+		}
+	}
+	return "", -1
+}
+
+// Return the index of the first instance of the ASCII substr in s, ignoring the case
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
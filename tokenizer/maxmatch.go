@@ -0,0 +1,134 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import "unicode/utf8"
+
+// CutFMMW cuts the text by forward maximum matching: from the beginning of the
+// text, the longest dictionary word is taken each time.
+func (sg *Segmenter) CutFMMW(s string, words *[]string) {
+	appendEnglishMerged(sg.forwardMaxMatch([]rune(s)), sg.GetCharClass(), words)
+}
+
+// CutBMMW cuts the text by backward maximum matching: from the end of the text,
+// the longest dictionary word is taken each time.
+func (sg *Segmenter) CutBMMW(s string, words *[]string) {
+	appendEnglishMerged(sg.backwardMaxMatch([]rune(s)), sg.GetCharClass(), words)
+}
+
+// CutBiMMW cuts the text by both forward and backward maximum matching, and keeps
+// the result with fewer words, then the one with fewer single characters. The
+// backward result is preferred in a tie.
+func (sg *Segmenter) CutBiMMW(s string, words *[]string) {
+	runes := []rune(s)
+	forward := make([]string, 0, DefaultWordsLen)
+	appendEnglishMerged(sg.forwardMaxMatch(runes), sg.GetCharClass(), &forward)
+	backward := make([]string, 0, DefaultWordsLen)
+	appendEnglishMerged(sg.backwardMaxMatch(runes), sg.GetCharClass(), &backward)
+
+	if len(forward) < len(backward) ||
+		(len(forward) == len(backward) && countSingleChars(forward) < countSingleChars(backward)) {
+		*words = append(*words, forward...)
+		return
+	}
+	*words = append(*words, backward...)
+}
+
+func (sg *Segmenter) getMaxWordLen() int {
+	sg.mu.RLock()
+	n := sg.maxWordLen
+	sg.mu.RUnlock()
+	if n > 0 {
+		return n
+	}
+	if n := GetDictionary().GetMaxWordLen(); n > 0 {
+		return n
+	}
+	return 1
+}
+
+func (sg *Segmenter) forwardMaxMatch(runes []rune) []string {
+	dictionary := GetDictionary()
+	maxLen := sg.getMaxWordLen()
+
+	wordsRet := make([]string, 0, DefaultWordsLen)
+	n := len(runes)
+	for i := 0; i < n; {
+		l := maxLen
+		if l > n-i {
+			l = n - i
+		}
+		for ; l > 1; l-- {
+			if freq, ok := dictionary.GetWord(string(runes[i : i+l])); ok && freq > 0 {
+				break
+			}
+		}
+		wordsRet = append(wordsRet, string(runes[i:i+l]))
+		i += l
+	}
+	return wordsRet
+}
+
+func (sg *Segmenter) backwardMaxMatch(runes []rune) []string {
+	dictionary := GetDictionary()
+	maxLen := sg.getMaxWordLen()
+
+	wordsRet := make([]string, 0, DefaultWordsLen)
+	for i := len(runes); i > 0; {
+		l := maxLen
+		if l > i {
+			l = i
+		}
+		for ; l > 1; l-- {
+			if freq, ok := dictionary.GetWord(string(runes[i-l : i])); ok && freq > 0 {
+				break
+			}
+		}
+		wordsRet = append(wordsRet, string(runes[i-l:i]))
+		i -= l
+	}
+
+	for i, j := 0, len(wordsRet)-1; i < j; i, j = i+1, j-1 {
+		wordsRet[i], wordsRet[j] = wordsRet[j], wordsRet[i]
+	}
+	return wordsRet
+}
+
+// Append the words, the consecutive single English characters and characters of the
+// extra classes of the classification are joined together the same way as CutNoHMMW.
+func appendEnglishMerged(wordsIn []string, class *CharClass, words *[]string) {
+	buf := ""
+	bufKind := runNone
+	for _, word := range wordsIn {
+		kind := runNone
+		if utf8.RuneCountInString(word) == 1 {
+			kind = class.runKind(word)
+		}
+		if len(buf) > 0 && kind != bufKind {
+			*words = append(*words, buf)
+			buf = ""
+		}
+		if kind != runNone {
+			buf += word
+			bufKind = kind
+			continue
+		}
+		*words = append(*words, word)
+	}
+
+	if len(buf) > 0 {
+		*words = append(*words, buf)
+	}
+}
+
+func countSingleChars(words []string) int {
+	count := 0
+	for _, word := range words {
+		if len([]rune(word)) == 1 {
+			count++
+		}
+	}
+	return count
+}
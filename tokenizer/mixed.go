@@ -0,0 +1,135 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MixedRule is the rule of cutting the runs of Latin letters and digits, such as
+// iPhone14Pro, v1.2.3 or GPT-4, see RegExpMixed.
+type MixedRule int
+
+const (
+	MixedKeep  MixedRule = iota // a run is a single word in all modes, the default
+	MixedSplit                  // a run is split at case and letter-digit boundaries and at symbols
+	MixedBoth                   // a run is a single word, and also split in the search mode
+)
+
+var mixedRuleNames = []string{"keep", "split", "both"}
+
+func (r MixedRule) String() string {
+	if r < 0 || int(r) >= len(mixedRuleNames) {
+		return mixedRuleNames[MixedKeep]
+	}
+	return mixedRuleNames[r]
+}
+
+// ParseMixedRule returns the rule of the name, MixedKeep for an unknown name
+func ParseMixedRule(name string) MixedRule {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, v := range mixedRuleNames {
+		if v == name {
+			return MixedRule(i)
+		}
+	}
+	return MixedKeep
+}
+
+// SplitMixedSeg splits the text into the mixed runs and the rest
+func SplitMixedSeg(s string) []string {
+	return splitRegExp(s, reMixed)
+}
+
+// IsMixedChars returns whether the text is a mixed run
+func IsMixedChars(s string) bool {
+	loc := reMixed.FindStringIndex(s)
+	return loc != nil && loc[0] == 0 && loc[1] == len(s)
+}
+
+// SplitMixed splits a mixed run at the boundaries between lower and upper case
+// letters, letters and digits, and at the symbols, which are words of their own:
+// iPhone14Pro to i Phone 14 Pro, HTTPServer to HTTP Server and GPT-4 to GPT - 4. The
+// digits joined by dots are a single number, such as 1.2.3 of v1.2.3.
+func SplitMixed(s string) []string {
+	parts := make([]string, 0, 4)
+	start := 0
+	prev := rune(0)
+	for i, r := range s {
+		if i > start && mixedBoundary(s, i, prev, r) {
+			parts = append(parts, s[start:i])
+			start = i
+		}
+		prev = r
+	}
+	if start < len(s) {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
+
+// Whether a part ends before the rune r at offset i, prev is the rune before it
+func mixedBoundary(s string, i int, prev, r rune) bool {
+	switch {
+	case isMixedSymbol(prev) && isMixedSymbol(r):
+		return false
+	case r == '.' && isDigit(prev) && i+1 < len(s) && isDigit(rune(s[i+1])):
+		// a decimal point or a dot of a version number
+		return false
+	case prev == '.' && isDigit(r) && i >= 2 && isDigit(rune(s[i-2])):
+		return false
+	case isMixedSymbol(prev) != isMixedSymbol(r):
+		return true
+	case isDigit(prev) != isDigit(r):
+		return true
+	case isLower(prev) && isUpper(r):
+		return true
+	case isUpper(prev) && isUpper(r):
+		// the last capital of an acronym starts the next word, HTTP Server
+		next, _ := utf8.DecodeRuneInString(s[i+1:])
+		return i+1 < len(s) && isLower(next)
+	}
+	return false
+}
+
+func isMixedSymbol(r rune) bool {
+	return !isDigit(r) && !isLower(r) && !isUpper(r)
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isLower(r rune) bool {
+	return r >= 'a' && r <= 'z'
+}
+
+func isUpper(r rune) bool {
+	return r >= 'A' && r <= 'Z'
+}
+
+// AppendMixedTokens appends the tokens of a mixed run by the rule, base is the offset
+// of the run. The parts of MixedBoth, without the symbols, are appended before the
+// whole run in the search mode, the way the search mode puts the short words first.
+func AppendMixedTokens(s string, base int, rule MixedRule, search bool, tokens *[]Token) {
+	switch {
+	case rule == MixedSplit:
+		AppendWordTokens(s, base, SplitMixed(s), tokens)
+		return
+	case rule == MixedBoth && search:
+		parts := SplitMixed(s)
+		if len(parts) > 1 {
+			words := make([]string, 0, len(parts))
+			for _, part := range parts {
+				if r, _ := utf8.DecodeRuneInString(part); !isMixedSymbol(r) {
+					words = append(words, part)
+				}
+			}
+			AppendWordTokens(s, base, words, tokens)
+		}
+	}
+	*tokens = append(*tokens, Token{Text: s, Start: base, End: base + len(s)})
+}
@@ -0,0 +1,209 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"math"
+	"sort"
+)
+
+// Segmentation is one way of cutting a sentence, scored by its log probability
+// under the unigram model of CalcDAG, or under the bigram model of CalcDAGBigram.
+type Segmentation struct {
+	Words []string `json:"words"`
+	Score float64  `json:"score"`
+}
+
+type Segmentations []Segmentation
+
+func (s Segmentations) Len() int {
+	return len(s)
+}
+
+func (s Segmentations) Less(i, j int) bool {
+	return s[i].Score > s[j].Score
+}
+
+func (s Segmentations) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// One of the k best routes from a position to the end of the sentence. The route
+// goes on with the rank-th best route from end+1.
+type nodeNBest struct {
+	score float64
+	end   int
+	rank  int
+}
+
+// NBest returns at most k best routes over the DAG, best first. The best one is
+// the same route as CalcDAG finds.
+func (s *Sentence) NBest(k int) Segmentations {
+	return mergeSegmentations(s.nBest(k), defaultCharClass)
+}
+
+// NBestBigram returns at most k best routes over the DAG under the bigram model,
+// best first. The best one is the same route as CalcDAGBigram finds.
+func (s *Sentence) NBestBigram(m *BigramModel, k int) Segmentations {
+	return mergeSegmentations(s.nBestBigram(m, k), defaultCharClass)
+}
+
+// NBest returns at most k best segmentations of the text without the recognizer,
+// scored by the bigram model of the segmenter if there is one, best first. The best
+// one is the segmentation of CutNoHMMW.
+func (sg *Segmenter) NBest(s string, k int) Segmentations {
+	sentence := NewSentence(s)
+	if bigram := sg.GetBigramModel(); bigram != nil {
+		return mergeSegmentations(sentence.nBestBigram(bigram, k), sg.GetCharClass())
+	}
+	return mergeSegmentations(sentence.nBest(k), sg.GetCharClass())
+}
+
+// Join the single English characters of the segmentations the same way as CutNoHMMW
+func mergeSegmentations(segmentations Segmentations, class *CharClass) Segmentations {
+	for i, v := range segmentations {
+		merged := make([]string, 0, len(v.Words))
+		appendEnglishMerged(v.Words, class, &merged)
+		segmentations[i].Words = merged
+	}
+	return segmentations
+}
+
+func (s *Sentence) nBest(k int) Segmentations {
+	n := s.Len()
+	if k <= 0 || n == 0 {
+		return Segmentations{}
+	}
+
+	dictionary := GetDictionary()
+	logTotal := math.Log(dictionary.GetTotalFreq())
+
+	dag := s.GetDAG()
+	routes := make([][]nodeNBest, n+1)
+	routes[n] = []nodeNBest{{0, 0, 0}}
+	for i := n - 1; i >= 0; i-- {
+		candidates := make([]nodeNBest, 0, len(dag[i])*k)
+		for _, x := range dag[i] {
+			freq, _ := dictionary.GetWord(s.GetWord(i, x+1))
+			if freq <= 0 {
+				freq = 1
+			}
+			val := math.Log(float64(freq)) - logTotal
+			for rank, next := range routes[x+1] {
+				candidates = append(candidates, nodeNBest{val + next.score, x, rank})
+			}
+		}
+
+		// the longer word first in a tie, the same as CalcDAG
+		sort.SliceStable(candidates, func(a, b int) bool {
+			if candidates[a].score != candidates[b].score {
+				return candidates[a].score > candidates[b].score
+			}
+			return candidates[a].end > candidates[b].end
+		})
+		if len(candidates) > k {
+			candidates = candidates[:k]
+		}
+		routes[i] = candidates
+	}
+
+	segmentations := make(Segmentations, 0, len(routes[0]))
+	for _, node := range routes[0] {
+		words := make([]string, 0, DefaultWordsLen)
+		for i, cur := 0, node; i < n; {
+			words = append(words, s.GetWord(i, cur.end+1))
+			i = cur.end + 1
+			cur = routes[i][cur.rank]
+		}
+		segmentations = append(segmentations, Segmentation{words, node.score})
+	}
+	return segmentations
+}
+
+// One of the k best prefixes of the sentence ending with a word. The prefix goes on
+// backwards with the rank-th best prefix ending with the word before, which starts
+// at prev, -1 if there is none.
+type nodeNBestBigram struct {
+	score float64
+	prev  int
+	rank  int
+}
+
+// The better candidates first, the same as CalcDAGBigram in a tie
+func sortNBestBigram(candidates []nodeNBestBigram, k int) []nodeNBestBigram {
+	sort.SliceStable(candidates, func(a, b int) bool {
+		if candidates[a].score != candidates[b].score {
+			return candidates[a].score > candidates[b].score
+		}
+		return candidates[a].prev > candidates[b].prev
+	})
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+	return candidates
+}
+
+func (s *Sentence) nBestBigram(m *BigramModel, k int) Segmentations {
+	n := s.Len()
+	if k <= 0 || n == 0 {
+		return Segmentations{}
+	}
+
+	// incoming[i] lists the starts of the words ending at i
+	dag := s.GetDAG()
+	incoming := make([][]int, n+1)
+	for i, ends := range dag {
+		for _, x := range ends {
+			incoming[x+1] = append(incoming[x+1], i)
+		}
+	}
+
+	// prefixes[i][x] are the k best prefixes of the sentence ending with word [i, x]
+	prefixes := make([]map[int][]nodeNBestBigram, n)
+	for i := 0; i < n; i++ {
+		prefixes[i] = make(map[int][]nodeNBestBigram, len(dag[i]))
+		for _, x := range dag[i] {
+			word := s.GetWord(i, x+1)
+			if i == 0 {
+				prefixes[i][x] = []nodeNBestBigram{{m.LogProb(BigramBegin, word), -1, 0}}
+				continue
+			}
+
+			candidates := make([]nodeNBestBigram, 0, k)
+			for _, p := range incoming[i] {
+				val := m.LogProb(s.GetWord(p, i), word)
+				for rank, prev := range prefixes[p][i-1] {
+					candidates = append(candidates, nodeNBestBigram{prev.score + val, p, rank})
+				}
+			}
+			if len(candidates) > 0 {
+				prefixes[i][x] = sortNBestBigram(candidates, k)
+			}
+		}
+	}
+
+	// the words ending the sentence, prev is the start of the last word
+	candidates := make([]nodeNBestBigram, 0, k)
+	for _, p := range incoming[n] {
+		for rank, v := range prefixes[p][n-1] {
+			candidates = append(candidates, nodeNBestBigram{v.score, p, rank})
+		}
+	}
+
+	segmentations := make(Segmentations, 0, k)
+	for _, node := range sortNBestBigram(candidates, k) {
+		words := make([]string, 0, DefaultWordsLen)
+		for start, end, rank := node.prev, n-1, node.rank; start >= 0; {
+			words = append(words, s.GetWord(start, end+1))
+			v := prefixes[start][end][rank]
+			start, end, rank = v.prev, start-1, v.rank
+		}
+		for i, j := 0, len(words)-1; i < j; i, j = i+1, j-1 {
+			words[i], words[j] = words[j], words[i]
+		}
+		segmentations = append(segmentations, Segmentation{words, node.score})
+	}
+	return segmentations
+}
@@ -0,0 +1,268 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	DefaultNewWordMinLen     = 2   // default minimal rune length of a candidate word
+	DefaultNewWordMaxLen     = 4   // default maximal rune length of a candidate word
+	DefaultNewWordMinFreq    = 5   // default minimal occurrences of a candidate word
+	DefaultNewWordMinPMI     = 2.0 // default minimal internal cohesion of a candidate word
+	DefaultNewWordMinEntropy = 1.0 // default minimal boundary entropy of a candidate word
+)
+
+// NewWordOptions controls how WordFinder scores and filters candidate words.
+// Zero values are replaced with the defaults above.
+type NewWordOptions struct {
+	MinLen     int
+	MaxLen     int
+	MinFreq    int
+	MinPMI     float64
+	MinEntropy float64
+}
+
+// WordCandidate is a new word found in a corpus, ready for review.
+type WordCandidate struct {
+	Word         string  `json:"word"`
+	Freq         int     `json:"freq"`          // occurrences in the corpus
+	PMI          float64 `json:"pmi"`           // minimal pointwise mutual information over all splits
+	LeftEntropy  float64 `json:"left_entropy"`  // entropy of the left neighbour characters
+	RightEntropy float64 `json:"right_entropy"` // entropy of the right neighbour characters
+	Score        float64 `json:"score"`
+	SuggestFreq  int     `json:"suggest_freq"` // frequency suggested for the dictionary
+}
+
+type WordCandidates []WordCandidate
+
+func (w WordCandidates) Len() int {
+	return len(w)
+}
+
+func (w WordCandidates) Less(i, j int) bool {
+	if w[i].Score != w[j].Score {
+		return w[i].Score > w[j].Score
+	}
+	return w[i].Word < w[j].Word
+}
+
+func (w WordCandidates) Swap(i, j int) {
+	w[i], w[j] = w[j], w[i]
+}
+
+// WriteUserDict writes the candidates in the user-defined dictionary format,
+// one "word freq prop" line per candidate.
+func (w WordCandidates) WriteUserDict(writer io.Writer, prop string) error {
+	if prop == "" {
+		prop = "n"
+	}
+	buf := bufio.NewWriter(writer)
+	for _, v := range w {
+		_, err := buf.WriteString(v.Word + " " + strconv.Itoa(v.SuggestFreq) + " " + prop + "\n")
+		if err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+type neighbours struct {
+	left, right         map[rune]int
+	leftEdge, rightEdge int // occurrences at the beginning or end of a text run
+}
+
+// WordFinder discovers words missing from the dictionary in raw text. Texts are
+// accumulated with Add, and Find ranks the candidate n-grams by frequency,
+// internal cohesion and boundary entropy.
+type WordFinder struct {
+	opts       NewWordOptions
+	ngrams     map[string]int
+	neighbours map[string]*neighbours
+	total      int // total count of Chinese characters
+}
+
+func NewWordFinder(opts ...NewWordOptions) *WordFinder {
+	o := NewWordOptions{}
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.MinLen < 2 {
+		o.MinLen = DefaultNewWordMinLen
+	}
+	if o.MaxLen < o.MinLen {
+		o.MaxLen = DefaultNewWordMaxLen
+		if o.MaxLen < o.MinLen {
+			o.MaxLen = o.MinLen
+		}
+	}
+	if o.MinFreq <= 0 {
+		o.MinFreq = DefaultNewWordMinFreq
+	}
+	if o.MinPMI == 0 {
+		o.MinPMI = DefaultNewWordMinPMI
+	}
+	if o.MinEntropy == 0 {
+		o.MinEntropy = DefaultNewWordMinEntropy
+	}
+	return &WordFinder{
+		opts:       o,
+		ngrams:     make(map[string]int),
+		neighbours: make(map[string]*neighbours),
+	}
+}
+
+// Add counts the n-grams of a text. Only runs of Chinese characters are
+// considered, anything else acts as a boundary.
+func (f *WordFinder) Add(s string) {
+	for _, segment := range SplitChineseSeg(s) {
+		if !IsChineseChars(segment) {
+			continue
+		}
+		runes := []rune(segment)
+		n := len(runes)
+		f.total += n
+		for i := 0; i < n; i++ {
+			for l := 1; l <= f.opts.MaxLen && i+l <= n; l++ {
+				word := string(runes[i : i+l])
+				f.ngrams[word]++
+				if l < f.opts.MinLen {
+					continue
+				}
+
+				nb, ok := f.neighbours[word]
+				if !ok {
+					nb = &neighbours{left: make(map[rune]int), right: make(map[rune]int)}
+					f.neighbours[word] = nb
+				}
+				if i > 0 {
+					nb.left[runes[i-1]]++
+				} else {
+					nb.leftEdge++
+				}
+				if i+l < n {
+					nb.right[runes[i+l]]++
+				} else {
+					nb.rightEdge++
+				}
+			}
+		}
+	}
+}
+
+// Find returns at most count candidates which are not in the dictionary,
+// best first. A count of zero returns all candidates.
+func (f *WordFinder) Find(count int) WordCandidates {
+	dictionary := GetDictionary()
+	stopWords := GetTFIDF()
+
+	wordsRet := make(WordCandidates, 0)
+	for word, nb := range f.neighbours {
+		freq := f.ngrams[word]
+		if freq < f.opts.MinFreq {
+			continue
+		}
+		if v, ok := dictionary.GetWord(word); ok && v > 0 {
+			continue
+		}
+
+		runes := []rune(word)
+		if stopWords.ExistStopWord(string(runes[0])) || stopWords.ExistStopWord(string(runes[len(runes)-1])) {
+			continue
+		}
+
+		pmi := f.pmi(runes, freq)
+		if pmi < f.opts.MinPMI {
+			continue
+		}
+
+		left := entropy(nb.left, nb.leftEdge)
+		right := entropy(nb.right, nb.rightEdge)
+		if math.Min(left, right) < f.opts.MinEntropy {
+			continue
+		}
+
+		wordsRet = append(wordsRet, WordCandidate{
+			Word:         word,
+			Freq:         freq,
+			PMI:          pmi,
+			LeftEntropy:  left,
+			RightEntropy: right,
+			Score:        math.Log(float64(freq)) * (pmi + math.Min(left, right)),
+			SuggestFreq:  SuggestFreq(word),
+		})
+	}
+
+	sort.Sort(wordsRet)
+	if count > 0 && count < len(wordsRet) {
+		wordsRet = wordsRet[:count]
+	}
+	return wordsRet
+}
+
+// pmi returns the minimal pointwise mutual information over all the ways of
+// splitting the word into two parts.
+func (f *WordFinder) pmi(runes []rune, freq int) float64 {
+	total := float64(f.total)
+	pmi := math.Inf(1)
+	for i := 1; i < len(runes); i++ {
+		left := f.ngrams[string(runes[:i])]
+		right := f.ngrams[string(runes[i:])]
+		v := math.Log(float64(freq) * total / (float64(left) * float64(right)))
+		if v < pmi {
+			pmi = v
+		}
+	}
+	return pmi
+}
+
+// entropy of the neighbour characters, every boundary counts as a distinct neighbour
+func entropy(counts map[rune]int, edges int) float64 {
+	total := edges
+	for _, v := range counts {
+		total += v
+	}
+	if total == 0 {
+		return 0
+	}
+
+	h := float64(0)
+	for _, v := range counts {
+		p := float64(v) / float64(total)
+		h -= p * math.Log(p)
+	}
+	p := 1 / float64(total)
+	h -= float64(edges) * p * math.Log(p)
+	return h
+}
+
+// SuggestFreq returns a frequency high enough for the word to be kept whole
+// when it is added to the dictionary.
+func SuggestFreq(word string) int {
+	dictionary := GetDictionary()
+	total := dictionary.GetTotalFreq()
+
+	words := make([]string, 0, DefaultWordsLen)
+	CutNoHMMW(word, &words)
+	freq := float64(1)
+	for _, w := range words {
+		v, _ := dictionary.GetWord(w)
+		if v <= 0 {
+			v = 1
+		}
+		freq *= float64(v) / total
+	}
+
+	suggest := int(freq*total) + 1
+	if v, ok := dictionary.GetWord(word); ok && v > suggest {
+		suggest = v
+	}
+	return suggest
+}
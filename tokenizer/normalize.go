@@ -0,0 +1,110 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeOptions are the steps of the normalization before segmentation
+type NormalizeOptions struct {
+	FullWidth     bool `json:"full_width"`     // full-width letters, digits and symbols to half-width, ideographic space to space
+	Compatibility bool `json:"compatibility"`  // NFKC folding of the compatibility characters, such as ① or ﬁ
+	CollapseSpace bool `json:"collapse_space"` // runs of whitespace to a single space, or a newline if there is one in the run
+	LowerCase     bool `json:"lower_case"`     // letters to lower case
+}
+
+// DefaultNormalizeOptions folds the width and the compatibility characters and
+// collapses whitespace, the case is kept.
+var DefaultNormalizeOptions = NormalizeOptions{
+	FullWidth:     true,
+	Compatibility: true,
+	CollapseSpace: true,
+}
+
+// Normalize normalizes the text with the options, the returned map keeps the
+// offsets of the normalized text in the original text.
+func Normalize(s string, opts NormalizeOptions) *TextMap {
+	m := newTextMap(s)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+
+		if opts.CollapseSpace && unicode.IsSpace(r) {
+			// the whole run of whitespace is a single character
+			end, newline := i, false
+			for end < len(s) {
+				c, n := utf8.DecodeRuneInString(s[end:])
+				if !unicode.IsSpace(c) {
+					break
+				}
+				newline = newline || c == '\n'
+				end += n
+			}
+			if newline {
+				m.write("\n", i, end)
+			} else {
+				m.write(" ", i, end)
+			}
+			i = end
+			continue
+		}
+
+		text, ok := foldRune(r, opts)
+		if !ok {
+			m.copyText(s[i:i+size], i)
+		} else {
+			m.write(text, i, i+size)
+		}
+		i += size
+	}
+	return m.done()
+}
+
+// Fold a rune with the options, false is returned if it is not changed
+func foldRune(r rune, opts NormalizeOptions) (string, bool) {
+	text, ok := string(r), false
+	if opts.FullWidth || opts.Compatibility {
+		if v, folded := foldWidth(r); folded {
+			text, ok = v, true
+		}
+	}
+	if !ok && opts.Compatibility {
+		text, ok = foldCompat(r)
+		if !ok {
+			text = string(r)
+		}
+	}
+	if opts.LowerCase {
+		if lower := strings.ToLower(text); lower != text {
+			text, ok = lower, true
+		}
+	}
+	return text, ok
+}
+
+// Fold the full-width ASCII characters and the ideographic space
+func foldWidth(r rune) (string, bool) {
+	switch {
+	case r >= '！' && r <= '～':
+		return string(r - 0xfee0), true
+	case r == '\u3000':
+		return " ", true
+	}
+	return "", false
+}
+
+// Fold the compatibility characters by NFKC, such as ⼀ to 一 or ㈱ to (株). The
+// fraction slash of the fractions is a slash, as in 1/2.
+func foldCompat(r rune) (string, bool) {
+	s := string(r)
+	if norm.NFKC.IsNormalString(s) {
+		return "", false
+	}
+	return strings.ReplaceAll(norm.NFKC.String(s), "\u2044", "/"), true
+}
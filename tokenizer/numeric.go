@@ -0,0 +1,511 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// types of the numeric entities
+const (
+	EntityNumber   = "NUMBER"
+	EntityMoney    = "MONEY"
+	EntityDate     = "DATE"
+	EntityTime     = "TIME"
+	EntityQuantity = "QUANTITY"
+)
+
+const (
+	exprArabic  = `\d+(?:,\d{3})*(?:\.\d+)?`
+	exprChinese = `[零〇一二两三四五六七八九十百千万亿壹贰叁肆伍陆柒捌玖拾佰仟]+(?:点[零〇一二三四五六七八九]+)?`
+	exprNumber  = `(?:` + exprArabic + `[万亿]?|` + exprChinese + `)`
+	exprSmall   = `(?:\d{1,2}|[零〇一二两三四五六七八九十]{1,3})` // month, day, hour, minute or second
+	exprPeriod  = `(?:上午|下午|中午|晚上|早上|早晨|凌晨|傍晚|夜里|夜间)`
+)
+
+var (
+	reDateYMD      = regexp.MustCompile(`^(\d{4}|[零〇一二三四五六七八九]{4})年(?:(` + exprSmall + `)月(?:(` + exprSmall + `)[日号])?)?`)
+	reDateMD       = regexp.MustCompile(`^(` + exprSmall + `)月(` + exprSmall + `)[日号]`)
+	reDateISO      = regexp.MustCompile(`^(\d{4})([-/.])(\d{1,2})([-/.])(\d{1,2})`)
+	reTimeChinese  = regexp.MustCompile(`^(` + exprPeriod + `)?(` + exprSmall + `)[点时](?:(半|一刻|三刻|(` + exprSmall + `)分)(?:(` + exprSmall + `)秒)?|(钟|整))?`)
+	reTimeClock    = regexp.MustCompile(`^(` + exprPeriod + `)?(\d{1,2}):(\d{2})(?::(\d{2}))?`)
+	reMoneySymbol  = regexp.MustCompile(`^([￥¥$€£])\s?(` + exprArabic + `)([万亿]?)`)
+	reMoneyUnit    = regexp.MustCompile(`^(` + exprNumber + `)(万元|亿元|块钱|元|块|美元|美金|欧元|英镑|日元|港元|港币|人民币|韩元)`)
+	reQuantity     = regexp.MustCompile(`^(` + exprNumber + `)(平方公里|平方千米|平方米|立方米|公斤|千克|毫克|克|吨|斤|公里|千米|厘米|毫米|米|英里|英尺|毫升|升|小时|分钟|秒钟|个月|天|周|年|岁|个|只|件|台|辆|次|人|位|张|本|条|份|家|层|页|篇|瓶|杯|碗|度|倍)`)
+	rePercent      = regexp.MustCompile(`^(` + exprArabic + `)%`)
+	rePercentCN    = regexp.MustCompile(`^百分之(` + exprChinese + `|` + exprArabic + `)`)
+	reNumberArabic = regexp.MustCompile(`^(` + exprArabic + `)([万亿]?)`)
+	reNumberCN     = regexp.MustCompile(`^` + exprChinese)
+)
+
+var currencyCodes = map[string]string{
+	"元": "CNY", "块": "CNY", "块钱": "CNY", "人民币": "CNY", "万元": "CNY", "亿元": "CNY",
+	"￥": "CNY", "¥": "CNY", "美元": "USD", "美金": "USD", "$": "USD", "欧元": "EUR",
+	"€": "EUR", "英镑": "GBP", "£": "GBP", "日元": "JPY", "港元": "HKD", "港币": "HKD",
+	"韩元": "KRW",
+}
+
+var unitCodes = map[string]string{
+	"公斤": "kg", "千克": "kg", "毫克": "mg", "克": "g", "吨": "t", "公里": "km", "千米": "km",
+	"厘米": "cm", "毫米": "mm", "米": "m", "毫升": "mL", "升": "L", "平方公里": "km2",
+	"平方千米": "km2", "平方米": "m2", "立方米": "m3", "小时": "h", "分钟": "min", "秒钟": "s",
+	"天": "d",
+}
+
+var chineseDigits = map[rune]int64{
+	'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8,
+	'九': 9, '壹': 1, '贰': 2, '叁': 3, '肆': 4, '伍': 5, '陆': 6, '柒': 7, '捌': 8, '玖': 9,
+}
+
+var chineseUnits = map[rune]int64{
+	'十': 10, '拾': 10, '百': 100, '佰': 100, '千': 1000, '仟': 1000,
+}
+
+// FindNumericSpans returns the spans of the numbers, money, dates, times and
+// quantities of the text. The type of a span is one of the Entity constants, and
+// the value is normalized: the numbers in Arabic numerals, the dates in ISO 8601,
+// such as 2022-03-05 or --03-05, the times in 24 hours, such as 15:30, the money
+// with the currency code, such as 3500 CNY, and the quantities with the unit, such
+// as 12.5 kg.
+func FindNumericSpans(s string) []Span {
+	spans := make([]Span, 0)
+	for i := 0; i < len(s); {
+		if !mayStartNumeric(s, i) {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+			continue
+		}
+
+		best := Span{Start: i, End: i}
+		for _, match := range numericMatchers {
+			end, typ, value, ok := match(s[i:])
+			if ok && i+end > best.End && !isAlnumAt(s, i+end) {
+				best = Span{Start: i, End: i + end, Type: typ, Value: value}
+			}
+		}
+		if best.End > i {
+			spans = append(spans, best)
+			i = best.End
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return spans
+}
+
+// the matchers in order of priority, the longest match wins
+var numericMatchers = []func(s string) (int, string, string, bool){
+	matchDate,
+	matchTime,
+	matchMoney,
+	matchQuantity,
+	matchNumber,
+}
+
+// Whether a numeric entity may start at i, a number in the middle of an English
+// word, such as H2O, is not one, nor is the end of a run of Chinese digits, such
+// as 八个 of 七八个
+func mayStartNumeric(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	if r >= '0' && r <= '9' {
+		return i == 0 || !(isAlnumAt(s, i-1) || s[i-1] == '.' || s[i-1] == '_')
+	}
+	_, isDigit := chineseDigits[r]
+	_, isUnit := chineseUnits[r]
+	if isDigit || isUnit {
+		prev, _ := utf8.DecodeLastRuneInString(s[:i])
+		_, ok := chineseDigits[prev]
+		return !ok
+	}
+	return strings.ContainsRune("￥¥$€£百上下中晚早凌傍夜", r)
+}
+
+func isAlnumAt(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return (c >= '0' && c <= '9') || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
+
+func matchDate(s string) (int, string, string, bool) {
+	if m := reDateISO.FindStringSubmatch(s); m != nil && m[2] == m[4] {
+		month, day := atoi(m[3]), atoi(m[5])
+		if validDate(month, day) {
+			return len(m[0]), EntityDate, fmt.Sprintf("%s-%02d-%02d", m[1], month, day), true
+		}
+	}
+	if m := reDateYMD.FindStringSubmatch(s); m != nil {
+		year := m[1]
+		if !isArabic(year) {
+			year = chineseDigitString(year)
+		}
+		if m[2] == "" {
+			return len(m[0]), EntityDate, year, true
+		}
+		month, ok := parseSmall(m[2])
+		if !ok || month < 1 || month > 12 {
+			return 0, "", "", false
+		}
+		if m[3] == "" {
+			return len(m[0]), EntityDate, fmt.Sprintf("%s-%02d", year, month), true
+		}
+		day, ok := parseSmall(m[3])
+		if !ok || !validDate(month, day) {
+			return 0, "", "", false
+		}
+		return len(m[0]), EntityDate, fmt.Sprintf("%s-%02d-%02d", year, month, day), true
+	}
+	if m := reDateMD.FindStringSubmatch(s); m != nil {
+		month, ok1 := parseSmall(m[1])
+		day, ok2 := parseSmall(m[2])
+		if ok1 && ok2 && validDate(month, day) {
+			return len(m[0]), EntityDate, fmt.Sprintf("--%02d-%02d", month, day), true
+		}
+	}
+	return 0, "", "", false
+}
+
+func matchTime(s string) (int, string, string, bool) {
+	if m := reTimeClock.FindStringSubmatch(s); m != nil {
+		hour, minute := atoi(m[2]), atoi(m[3])
+		second := -1
+		if m[4] != "" {
+			second = atoi(m[4])
+		}
+		if value, ok := formatTime(m[1], hour, minute, second); ok {
+			return len(m[0]), EntityTime, value, true
+		}
+	}
+	if m := reTimeChinese.FindStringSubmatch(s); m != nil {
+		// a bare Chinese hour, such as 一点, is too often not a time
+		if m[1] == "" && m[3] == "" && m[6] == "" && !isArabic(m[2]) {
+			return 0, "", "", false
+		}
+		hour, ok := parseSmall(m[2])
+		if !ok {
+			return 0, "", "", false
+		}
+		minute, second := 0, -1
+		switch m[3] {
+		case "":
+		case "半":
+			minute = 30
+		case "一刻":
+			minute = 15
+		case "三刻":
+			minute = 45
+		default:
+			if minute, ok = parseSmall(m[4]); !ok {
+				return 0, "", "", false
+			}
+			if m[5] != "" {
+				if second, ok = parseSmall(m[5]); !ok {
+					return 0, "", "", false
+				}
+			}
+		}
+		if value, ok := formatTime(m[1], hour, minute, second); ok {
+			return len(m[0]), EntityTime, value, true
+		}
+	}
+	return 0, "", "", false
+}
+
+func matchMoney(s string) (int, string, string, bool) {
+	if m := reMoneySymbol.FindStringSubmatch(s); m != nil {
+		value := parseArabic(m[2], m[3])
+		return len(m[0]), EntityMoney, value + " " + currencyCodes[m[1]], true
+	}
+	if m := reMoneyUnit.FindStringSubmatch(s); m != nil {
+		value, ok := parseNumber(m[1])
+		if !ok {
+			return 0, "", "", false
+		}
+		switch m[2] {
+		case "万元":
+			value = shiftDecimal(value, 4)
+		case "亿元":
+			value = shiftDecimal(value, 8)
+		}
+		return len(m[0]), EntityMoney, value + " " + currencyCodes[m[2]], true
+	}
+	return 0, "", "", false
+}
+
+func matchQuantity(s string) (int, string, string, bool) {
+	m := reQuantity.FindStringSubmatch(s)
+	if m == nil {
+		return 0, "", "", false
+	}
+	value, ok := parseNumber(m[1])
+	if !ok {
+		return 0, "", "", false
+	}
+	unit := m[2]
+	if code, ok := unitCodes[unit]; ok {
+		unit = code
+	}
+	return len(m[0]), EntityQuantity, value + " " + unit, true
+}
+
+func matchNumber(s string) (int, string, string, bool) {
+	if m := rePercent.FindStringSubmatch(s); m != nil {
+		return len(m[0]), EntityNumber, parseArabic(m[1], "") + "%", true
+	}
+	if m := rePercentCN.FindStringSubmatch(s); m != nil {
+		if value, ok := parseNumber(m[1]); ok {
+			return len(m[0]), EntityNumber, value + "%", true
+		}
+	}
+	if m := reNumberArabic.FindStringSubmatch(s); m != nil {
+		return len(m[0]), EntityNumber, parseArabic(m[1], m[2]), true
+	}
+	if text := reNumberCN.FindString(s); text != "" {
+		// a Chinese number on its own needs a unit, a zero or a decimal point,
+		// such as 三千五 or 二〇二二, otherwise it is more likely a word, such as 一一
+		if utf8.RuneCountInString(text) < 2 || !strings.ContainsAny(text, "十百千万亿拾佰仟零〇点") {
+			return 0, "", "", false
+		}
+		if value, ok := parseChinese(text); ok {
+			return len(text), EntityNumber, value, true
+		}
+	}
+	return 0, "", "", false
+}
+
+// Parse an Arabic or Chinese number into Arabic numerals
+func parseNumber(s string) (string, bool) {
+	if m := reNumberArabic.FindStringSubmatch(s); m != nil && len(m[0]) == len(s) {
+		return parseArabic(m[1], m[2]), true
+	}
+	return parseChinese(s)
+}
+
+// Parse an Arabic number with an optional 万 or 亿
+func parseArabic(s, magnitude string) string {
+	s = strings.ReplaceAll(s, ",", "")
+	switch magnitude {
+	case "万":
+		return shiftDecimal(s, 4)
+	case "亿":
+		return shiftDecimal(s, 8)
+	}
+	return s
+}
+
+// ParseChineseNumber parses a number in Chinese numerals, such as 三千五百, 一万零三,
+// 二〇二二 or 三点一四, into Arabic numerals. A run of digits without units is a
+// number only if it is written like a year, such as 一九九八, not 七八.
+func ParseChineseNumber(s string) (string, bool) {
+	return parseChinese(s)
+}
+
+func parseChinese(s string) (string, bool) {
+	integer, fraction := s, ""
+	if i := strings.Index(s, "点"); i >= 0 {
+		integer, fraction = s[:i], s[i+len("点"):]
+		if fraction == "" {
+			return "", false
+		}
+		fraction = chineseDigitString(fraction)
+		if fraction == "" {
+			return "", false
+		}
+	}
+
+	value := ""
+	if integer == "" {
+		if fraction == "" {
+			return "", false
+		}
+		value = "0"
+	} else if !strings.ContainsAny(integer, "十百千万亿拾佰仟") {
+		// digit by digit, such as 二〇二二 or 一九九八, the other runs of digits are
+		// approximations, such as 七八 of 七八个
+		n := utf8.RuneCountInString(integer)
+		if n > 1 && n != 4 && !strings.ContainsAny(integer, "零〇") {
+			return "", false
+		}
+		value = chineseDigitString(integer)
+		if value == "" {
+			return "", false
+		}
+	} else {
+		n, ok := parseChineseInteger(integer)
+		if !ok {
+			return "", false
+		}
+		value = strconv.FormatInt(n, 10)
+	}
+
+	if fraction != "" {
+		value += "." + fraction
+	}
+	return value, true
+}
+
+// Turn the Chinese digits into Arabic digits one by one
+func chineseDigitString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		d, ok := chineseDigits[r]
+		if !ok {
+			return ""
+		}
+		b.WriteByte(byte('0' + d))
+	}
+	return b.String()
+}
+
+// Parse a Chinese integer with units, such as 三千五百 or 五万亿
+func parseChineseInteger(s string) (int64, bool) {
+	var total, section, number int64
+	lastUnit := int64(0) // the unit in front of the last digit, for the elided ones such as 三千五
+	prevDigit := false
+	first := true
+	for _, r := range s {
+		if d, ok := chineseDigits[r]; ok {
+			if prevDigit && d != 0 && number != 0 {
+				// two digits in a row, such as 三五千
+				return 0, false
+			}
+			number = d
+			prevDigit = true
+			if d == 0 {
+				lastUnit = 0
+			}
+		} else if u, ok := chineseUnits[r]; ok {
+			if !prevDigit {
+				if !first || u != 10 {
+					return 0, false
+				}
+				number = 1
+			}
+			section += number * u
+			number = 0
+			lastUnit = u
+			prevDigit = false
+		} else if r == '万' || r == '亿' {
+			if first {
+				return 0, false
+			}
+			magnitude := int64(10000)
+			if r == '亿' {
+				magnitude = 100000000
+			}
+			if r == '万' {
+				total += (section + number) * magnitude
+			} else {
+				total = (total + section + number) * magnitude
+			}
+			section, number = 0, 0
+			lastUnit = magnitude
+			prevDigit = false
+		} else {
+			return 0, false
+		}
+		first = false
+	}
+	// the elided unit of the last digit, such as 三千五 or 一万五
+	if number != 0 && prevDigit && lastUnit >= 100 {
+		runes := []rune(s)
+		if len(runes) >= 2 {
+			if _, ok := chineseDigits[runes[len(runes)-2]]; !ok {
+				number *= lastUnit / 10
+			}
+		}
+	}
+	return total + section + number, true
+}
+
+// Parse a month, day, hour, minute or second
+func parseSmall(s string) (int, bool) {
+	if isArabic(s) {
+		return atoi(s), true
+	}
+	n, ok := parseChineseInteger(s)
+	if !ok {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// Move the decimal point of an Arabic number n places to the right
+func shiftDecimal(s string, n int) string {
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+	for len(fraction) < n {
+		fraction += "0"
+	}
+	integer += fraction[:n]
+	fraction = strings.TrimRight(fraction[n:], "0")
+	integer = strings.TrimLeft(integer, "0")
+	if integer == "" {
+		integer = "0"
+	}
+	if fraction != "" {
+		return integer + "." + fraction
+	}
+	return integer
+}
+
+// Format the time of day in 24 hours, the hours in the afternoon or evening are
+// moved 12 hours later and 12 o'clock at night is midnight. A negative second is
+// left out.
+func formatTime(period string, hour, minute, second int) (string, bool) {
+	switch period {
+	case "下午", "傍晚":
+		if hour < 12 {
+			hour += 12
+		}
+	case "晚上", "夜里", "夜间":
+		if hour == 12 {
+			hour = 0
+		} else if hour < 12 {
+			hour += 12
+		}
+	case "凌晨":
+		if hour == 12 {
+			hour = 0
+		}
+	case "中午":
+		if hour < 11 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 || second > 59 {
+		return "", false
+	}
+	if second >= 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", hour, minute, second), true
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute), true
+}
+
+func validDate(month, day int) bool {
+	return month >= 1 && month <= 12 && day >= 1 && day <= 31
+}
+
+func isArabic(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
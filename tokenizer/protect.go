@@ -0,0 +1,80 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+)
+
+// Span is a byte range of a text which is kept as a single token labelled with Type.
+type Span struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"` // normalized value, such as the ISO date of a date
+}
+
+// Pattern is a named regular expression, its matches are kept as single tokens
+// labelled with the name, such as URLs, emails or SKUs.
+type Pattern struct {
+	Name string
+	re   *regexp.Regexp
+}
+
+func NewPattern(name, expr string) (*Pattern, error) {
+	if name == "" {
+		return nil, errors.New("the pattern name is empty")
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Pattern{Name: name, re: re}, nil
+}
+
+// FindSpans returns the spans of all the non-empty matches in the text
+func (p *Pattern) FindSpans(s string) []Span {
+	spans := make([]Span, 0)
+	for _, loc := range p.re.FindAllStringIndex(s, -1) {
+		if loc[1] > loc[0] {
+			spans = append(spans, Span{Start: loc[0], End: loc[1], Type: p.Name})
+		}
+	}
+	return spans
+}
+
+// ResolveSpans returns the valid spans of the text in order of their offsets. A span
+// overlapping one in front of it in the list is dropped, and so is a span out of the
+// text or not on rune boundaries.
+func ResolveSpans(s string, spans []Span) []Span {
+	resolved := make([]Span, 0, len(spans))
+	for _, span := range spans {
+		if span.Start < 0 || span.End > len(s) || span.Start >= span.End {
+			continue
+		}
+		if !utf8.RuneStart(s[span.Start]) || (span.End < len(s) && !utf8.RuneStart(s[span.End])) {
+			continue
+		}
+
+		overlapped := false
+		for _, v := range resolved {
+			if span.Start < v.End && v.Start < span.End {
+				overlapped = true
+				break
+			}
+		}
+		if !overlapped {
+			resolved = append(resolved, span)
+		}
+	}
+
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].Start < resolved[j].Start
+	})
+	return resolved
+}
@@ -0,0 +1,28 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+// Recognizer cuts a run of characters which the dictionary route leaves as single
+// characters, recognizing the unknown words in it. The HMM based FinalSeg is the
+// default recognizer, it is also used if the words of a recognizer do not join
+// back into the run, or if their states do not match them one to one.
+type Recognizer interface {
+	Cut(sentence string) []string
+}
+
+// RecognizerFunc adapts an ordinary function to a Recognizer.
+type RecognizerFunc func(sentence string) []string
+
+func (f RecognizerFunc) Cut(sentence string) []string {
+	return f(sentence)
+}
+
+// StateRecognizer is a Recognizer which also reports the states of the words it
+// recognizes, such as the "BME" tags of the HMM. Words not cut by the model have
+// empty states.
+type StateRecognizer interface {
+	Recognizer
+	CutStates(sentence string) (words []string, states []string)
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenScore is the contribution of a word to the score of a segmentation.
+type TokenScore struct {
+	Word  string  `json:"word"`
+	Freq  int     `json:"freq"`
+	Score float64 `json:"score"`
+}
+
+// SegmentationScore is the log probability of a segmentation under the unigram
+// model of CalcDAG, the sum of the scores of its words.
+type SegmentationScore struct {
+	Tokens []TokenScore `json:"tokens"`
+	Score  float64      `json:"score"`
+}
+
+// ScoreWords scores a segmentation of the sentence. The words must cover the
+// sentence in order, but the whitespace between them may be left out. Whitespace
+// words score nothing, and the other words score log(freq/total) with the
+// frequency of an unknown word taken as 1, the same as CalcDAG.
+func ScoreWords(s string, words []string) (*SegmentationScore, error) {
+	dictionary := GetDictionary()
+	logTotal := math.Log(dictionary.GetTotalFreq())
+
+	result := &SegmentationScore{
+		Tokens: make([]TokenScore, 0, len(words)),
+	}
+	pos := 0
+	for _, word := range words {
+		if word == "" {
+			return nil, fmt.Errorf("the word at byte offset %d is empty", pos)
+		}
+		for !strings.HasPrefix(s[pos:], word) {
+			r, n := utf8.DecodeRuneInString(s[pos:])
+			if n == 0 || !unicode.IsSpace(r) {
+				return nil, fmt.Errorf("the word %q does not match the sentence at byte offset %d", word, pos)
+			}
+			pos += n
+		}
+		pos += len(word)
+
+		if strings.TrimSpace(word) == "" {
+			result.Tokens = append(result.Tokens, TokenScore{Word: word})
+			continue
+		}
+		freq, _ := dictionary.GetWord(word)
+		if freq < 0 {
+			freq = 0
+		}
+		score := -logTotal
+		if freq > 0 {
+			score = math.Log(float64(freq)) - logTotal
+		}
+		result.Tokens = append(result.Tokens, TokenScore{word, freq, score})
+		result.Score += score
+	}
+
+	if strings.TrimSpace(s[pos:]) != "" {
+		return nil, fmt.Errorf("the words do not cover the sentence from byte offset %d", pos)
+	}
+	return result, nil
+}
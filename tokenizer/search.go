@@ -0,0 +1,73 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"sort"
+	"unicode"
+)
+
+// SearchOptions are the sub-words of the long words in the search mode
+type SearchOptions struct {
+	MaxGram     int  `json:"max_gram"`     // rune length of the longest dictionary sub-words, 3 by default, 1 for none
+	SingleChars bool `json:"single_chars"` // emit every Chinese character of the words for recall
+	NoParent    bool `json:"no_parent"`    // drop the words which have sub-words
+}
+
+// DefaultSearchOptions emits the dictionary sub-words of 2 and 3 runes and the words
+var DefaultSearchOptions = SearchOptions{
+	MaxGram: 3,
+}
+
+// AppendSearchTokens appends the word and its sub-words: the dictionary sub-words of
+// 2 to MaxGram runes which are shorter than the word, and the single characters if
+// SingleChars is on. The tokens are ordered by position, the ones starting at the
+// same position by length, so that they can be indexed with position increments.
+func AppendSearchTokens(word Token, opts SearchOptions, tokens *[]Token) {
+	maxGram := opts.MaxGram
+	if maxGram <= 0 {
+		maxGram = DefaultSearchOptions.MaxGram
+	}
+
+	wordRune := []rune(word.Text)
+	if len(wordRune) < 2 {
+		*tokens = append(*tokens, word)
+		return
+	}
+	offsets := make([]int, 0, len(wordRune)+1)
+	for i := range word.Text {
+		offsets = append(offsets, word.Start+i)
+	}
+	offsets = append(offsets, word.Start+len(word.Text))
+
+	words := make([]Token, 0, len(wordRune)+1)
+	if opts.SingleChars {
+		for i, r := range wordRune {
+			if !unicode.Is(unicode.Han, r) {
+				continue
+			}
+			words = append(words, Token{Text: string(r), Start: offsets[i], End: offsets[i+1]})
+		}
+	}
+	for n := 2; n <= maxGram && n < len(wordRune); n++ {
+		for i := 0; i+n <= len(wordRune); i++ {
+			s := string(wordRune[i : i+n])
+			if dictionary.Exist(s) {
+				words = append(words, Token{Text: s, Start: offsets[i], End: offsets[i+n]})
+			}
+		}
+	}
+	if !opts.NoParent || len(words) == 0 {
+		words = append(words, word)
+	}
+
+	sort.SliceStable(words, func(i, j int) bool {
+		if words[i].Start != words[j].Start {
+			return words[i].Start < words[j].Start
+		}
+		return words[i].End < words[j].End
+	})
+	*tokens = append(*tokens, words...)
+}
@@ -0,0 +1,290 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import "sync"
+
+var defaultSegmenter = &Segmenter{}
+
+// Segmenter holds the settings of the word cutting functions which can differ
+// between instances. The package level cutting functions use a default segmenter.
+type Segmenter struct {
+	recognizer Recognizer
+	bigram     *BigramModel
+	maxWordLen int // maximal word length of maximum matching
+	normalize  *NormalizeOptions
+	charClass  *CharClass
+	numeric    bool      // whether the numeric entities are kept as single tokens
+	mixed      MixedRule // rule of the mixed Latin and digit runs
+	english    *EnglishFilter
+	filter     *TokenFilter
+	search     *SearchOptions
+	mu         sync.RWMutex // the settings above may be changed while cutting
+
+	patterns   []*Pattern // patterns of the protected spans
+	patternsMu sync.RWMutex
+}
+
+func NewSegmenter() *Segmenter {
+	return &Segmenter{}
+}
+
+// GetRecognizer returns the recognizer for unknown words, FinalSeg if none is set.
+func (sg *Segmenter) GetRecognizer() Recognizer {
+	sg.mu.RLock()
+	defer sg.mu.RUnlock()
+	if sg.recognizer == nil {
+		return GetFinalSeg()
+	}
+	return sg.recognizer
+}
+
+// SetRecognizer replaces the recognizer for unknown words, nil restores FinalSeg.
+func (sg *Segmenter) SetRecognizer(r Recognizer) {
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+	sg.recognizer = r
+}
+
+// GetBigramModel returns the bigram model used for route scoring, nil if the
+// unigram model of the dictionary is used.
+func (sg *Segmenter) GetBigramModel() *BigramModel {
+	sg.mu.RLock()
+	defer sg.mu.RUnlock()
+	return sg.bigram
+}
+
+// SetBigramModel enables the bigram model for route scoring, nil restores the
+// unigram model.
+func (sg *Segmenter) SetBigramModel(m *BigramModel) {
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+	sg.bigram = m
+}
+
+// SetMaxWordLen sets the maximal rune length of the words in maximum matching,
+// 0 uses the length of the longest dictionary word.
+func (sg *Segmenter) SetMaxWordLen(n int) {
+	if n < 0 {
+		n = 0
+	}
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+	sg.maxWordLen = n
+}
+
+// GetCharClass returns the character classification of the segmenter
+func (sg *Segmenter) GetCharClass() *CharClass {
+	sg.mu.RLock()
+	defer sg.mu.RUnlock()
+	if sg.charClass == nil {
+		return defaultCharClass
+	}
+	return sg.charClass
+}
+
+// SetCharClass replaces the character classification, nil restores the default one.
+func (sg *Segmenter) SetCharClass(c *CharClass) {
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+	sg.charClass = c
+}
+
+// SetNormalizeOptions enables the normalization of the text before cutting, nil
+// disables it.
+func (sg *Segmenter) SetNormalizeOptions(opts *NormalizeOptions) {
+	if opts != nil {
+		v := *opts
+		opts = &v
+	}
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+	sg.normalize = opts
+}
+
+// Normalize normalizes the text with the options of the segmenter, nil is returned
+// if the normalization is not enabled.
+func (sg *Segmenter) Normalize(s string) *TextMap {
+	sg.mu.RLock()
+	opts := sg.normalize
+	sg.mu.RUnlock()
+	if opts == nil {
+		return nil
+	}
+	return Normalize(s, *opts)
+}
+
+// SetEnglishFilter enables the filter of the English words of the search mode and
+// the keyword extraction, nil disables it.
+func (sg *Segmenter) SetEnglishFilter(f *EnglishFilter) {
+	if f != nil {
+		v := *f
+		f = &v
+	}
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+	sg.english = f
+}
+
+// FilterEnglish filters the English words with the filter of the segmenter, the
+// words are returned as they are if the filter is not enabled.
+func (sg *Segmenter) FilterEnglish(words []string) []string {
+	sg.mu.RLock()
+	f := sg.english
+	sg.mu.RUnlock()
+	if f == nil {
+		return words
+	}
+	for i, word := range words {
+		words[i] = f.Filter(word)
+	}
+	return words
+}
+
+// FilterEnglishTokens filters the English words of the tokens in place, the same
+// way as FilterEnglish
+func (sg *Segmenter) FilterEnglishTokens(tokens []Token) {
+	sg.mu.RLock()
+	f := sg.english
+	sg.mu.RUnlock()
+	if f == nil {
+		return
+	}
+	for i := range tokens {
+		tokens[i].Text = f.Filter(tokens[i].Text)
+	}
+}
+
+// GetTokenFilter returns the filter of the words, nil if none is set
+func (sg *Segmenter) GetTokenFilter() *TokenFilter {
+	sg.mu.RLock()
+	defer sg.mu.RUnlock()
+	return sg.filter
+}
+
+// SetTokenFilter sets the filter of the words, nil disables it
+func (sg *Segmenter) SetTokenFilter(filter *TokenFilter) {
+	if filter != nil {
+		v := *filter
+		filter = &v
+	}
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+	sg.filter = filter
+}
+
+// GetSearchOptions returns the sub-words of the search mode, DefaultSearchOptions
+// if none is set
+func (sg *Segmenter) GetSearchOptions() SearchOptions {
+	sg.mu.RLock()
+	defer sg.mu.RUnlock()
+	if sg.search == nil {
+		return DefaultSearchOptions
+	}
+	return *sg.search
+}
+
+// SetSearchOptions sets the sub-words of the search mode, nil restores the defaults
+func (sg *Segmenter) SetSearchOptions(opts *SearchOptions) {
+	if opts != nil {
+		v := *opts
+		opts = &v
+	}
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+	sg.search = opts
+}
+
+// AddPattern registers a named regular expression whose matches are kept as single
+// tokens, a pattern of the same name is replaced.
+func (sg *Segmenter) AddPattern(name, expr string) error {
+	p, err := NewPattern(name, expr)
+	if err != nil {
+		return err
+	}
+
+	sg.patternsMu.Lock()
+	defer sg.patternsMu.Unlock()
+	for i, v := range sg.patterns {
+		if v.Name == name {
+			sg.patterns[i] = p
+			return nil
+		}
+	}
+	sg.patterns = append(sg.patterns, p)
+	return nil
+}
+
+// RemovePattern removes the pattern of the name
+func (sg *Segmenter) RemovePattern(name string) {
+	sg.patternsMu.Lock()
+	defer sg.patternsMu.Unlock()
+	for i, v := range sg.patterns {
+		if v.Name == name {
+			sg.patterns = append(sg.patterns[:i], sg.patterns[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetNumericEntities sets whether the numbers, money, dates, times and quantities
+// are kept as single tokens, see FindNumericSpans.
+func (sg *Segmenter) SetNumericEntities(enable bool) {
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+	sg.numeric = enable
+}
+
+// GetMixedRule returns the rule of cutting the mixed Latin and digit runs
+func (sg *Segmenter) GetMixedRule() MixedRule {
+	sg.mu.RLock()
+	defer sg.mu.RUnlock()
+	return sg.mixed
+}
+
+// SetMixedRule sets the rule of cutting the mixed Latin and digit runs, such as
+// iPhone14Pro or v1.2.3, they are single words by default.
+func (sg *Segmenter) SetMixedRule(rule MixedRule) {
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+	sg.mixed = rule
+}
+
+// ProtectedSpans returns the spans of the text kept as single tokens: the given
+// spans, then the matches of the patterns in the order they are added, then the
+// numeric entities if they are enabled. A span overlapping an earlier one is dropped.
+func (sg *Segmenter) ProtectedSpans(s string, spans []Span) []Span {
+	sg.mu.RLock()
+	numeric := sg.numeric
+	sg.mu.RUnlock()
+
+	sg.patternsMu.RLock()
+	defer sg.patternsMu.RUnlock()
+	if len(spans) == 0 && len(sg.patterns) == 0 && !numeric {
+		return nil
+	}
+
+	all := make([]Span, 0, len(spans))
+	all = append(all, spans...)
+	for _, p := range sg.patterns {
+		all = append(all, p.FindSpans(s)...)
+	}
+	if numeric {
+		all = append(all, FindNumericSpans(s)...)
+	}
+	return ResolveSpans(s, all)
+}
+
+// Calculate the best route of the sentence with the model of the segmenter
+func (sg *Segmenter) calcRoute(sentence *Sentence) []NodeDAG {
+	if bigram := sg.GetBigramModel(); bigram != nil {
+		return sentence.CalcDAGBigram(bigram)
+	}
+	return sentence.CalcDAG()
+}
+
+func GetSegmenter() *Segmenter {
+	return defaultSegmenter
+}
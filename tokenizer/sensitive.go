@@ -0,0 +1,350 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	SensitiveDefault = "SENSITIVE" // category of the sensitive words listed without one
+
+	DefaultSensitiveSkip = 3 // default number of separators allowed between two characters of a word
+)
+
+// the folding of the text matched against the sensitive words
+var sensitiveNormalizeOptions = NormalizeOptions{
+	FullWidth:     true,
+	Compatibility: true,
+	LowerCase:     true,
+}
+
+var sensitiveWords = NewSensitiveWords()
+
+// SensitiveWords is a list of sensitive words with their categories, matched by an
+// Aho-Corasick automaton. The term file has a word per line: "word<SPACE>category",
+// the words without a category are SENSITIVE. The words and the text are folded by
+// Normalize, to lower case and from the traditional characters to the simplified
+// ones, so that ＳＥＸ and 賭博 are caught by sex and 赌博. The separators, which
+// are punctuation, symbols, whitespace and invisible format characters, are skipped
+// between the characters of a word, so that 赌 * 博 is caught as well.
+type SensitiveWords struct {
+	words   map[string]sensitiveWord // folded word to the word
+	nodes   []sensitiveNode          // the automaton, nodes[0] is the root
+	maxSkip int                      // maximal number of separators between two characters
+	mu      sync.RWMutex
+}
+
+type sensitiveWord struct {
+	word     string // the word as listed
+	category string
+}
+
+type sensitiveNode struct {
+	next   map[rune]int
+	fail   int
+	word   string // folded word ending at the node, empty if none
+	length int    // rune length of the folded word
+	output int    // the nearest node with a word on the fail chain, -1 if none
+}
+
+func NewSensitiveWords() *SensitiveWords {
+	w := &SensitiveWords{
+		words:   make(map[string]sensitiveWord),
+		maxSkip: DefaultSensitiveSkip,
+	}
+	w.build()
+	return w
+}
+
+// SetMaxSkip sets the maximal number of separators between two characters of a
+// word, 0 matches the words written without separators only
+func (w *SensitiveWords) SetMaxSkip(n int) {
+	if n < 0 {
+		n = 0
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.maxSkip = n
+}
+
+// Load loads a sensitive words file
+func (w *SensitiveWords) Load(file string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	timeStart := time.Now()
+
+	f, err := os.Open(file)
+	if err != nil {
+		log.Println(err)
+		return errors.New("unable to load the sensitive words file:" + filepath.Base(file))
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	itemCount := 0
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			break
+		}
+
+		elem := strings.Fields(line)
+		if len(elem) == 0 || len(elem) > 2 || strings.HasPrefix(elem[0], "#") {
+			if err == io.EOF {
+				break
+			}
+			continue
+		}
+
+		category := SensitiveDefault
+		if len(elem) == 2 {
+			category = elem[1]
+		}
+		if w.add(elem[0], category) {
+			itemCount++
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+	w.build()
+
+	log.Printf("%v words are loaded in sensitive words file "+filepath.Base(file)+", and take %v\n",
+		itemCount, time.Now().Sub(timeStart))
+	return nil
+}
+
+// AddWord adds a sensitive word of the category, SENSITIVE if the category is empty
+func (w *SensitiveWords) AddWord(word, category string) error {
+	category = strings.TrimSpace(category)
+	if category == "" {
+		category = SensitiveDefault
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.add(strings.TrimSpace(word), category) {
+		return errors.New("the sensitive word is empty")
+	}
+	w.build()
+	return nil
+}
+
+// Add the word unless it is empty after folding
+func (w *SensitiveWords) add(word, category string) bool {
+	var b strings.Builder
+	for _, r := range word {
+		for _, c := range foldSensitive(r) {
+			if !isSensitiveSeparator(c) {
+				b.WriteRune(c)
+			}
+		}
+	}
+	if b.Len() == 0 {
+		return false
+	}
+	w.words[b.String()] = sensitiveWord{word: word, category: strings.ToUpper(category)}
+	return true
+}
+
+// Build the automaton of the words
+func (w *SensitiveWords) build() {
+	w.nodes = []sensitiveNode{{next: make(map[rune]int), output: -1}}
+	for folded := range w.words {
+		n := 0
+		length := 0
+		for _, r := range folded {
+			next, ok := w.nodes[n].next[r]
+			if !ok {
+				next = len(w.nodes)
+				w.nodes = append(w.nodes, sensitiveNode{next: make(map[rune]int), output: -1})
+				w.nodes[n].next[r] = next
+			}
+			n = next
+			length++
+		}
+		w.nodes[n].word = folded
+		w.nodes[n].length = length
+	}
+
+	// the fail links in breadth-first order
+	queue := make([]int, 0, len(w.nodes))
+	for _, next := range w.nodes[0].next {
+		queue = append(queue, next)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for r, next := range w.nodes[n].next {
+			fail := w.nodes[n].fail
+			for fail > 0 {
+				if _, ok := w.nodes[fail].next[r]; ok {
+					break
+				}
+				fail = w.nodes[fail].fail
+			}
+			if v, ok := w.nodes[fail].next[r]; ok && v != next {
+				fail = v
+			} else {
+				fail = 0
+			}
+			w.nodes[next].fail = fail
+			if w.nodes[fail].word != "" {
+				w.nodes[next].output = fail
+			} else {
+				w.nodes[next].output = w.nodes[fail].output
+			}
+			queue = append(queue, next)
+		}
+	}
+}
+
+// Len returns the number of the sensitive words
+func (w *SensitiveWords) Len() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.words)
+}
+
+// FindAll returns the sensitive words in the text, the leftmost and longest ones
+// win over the words overlapping them. The Text of a token is the text matched,
+// the Type is the category and the Value is the word as listed. A word starting or
+// ending with a letter or digit is not matched inside a word.
+func (w *SensitiveWords) FindAll(s string) []Token {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if len(w.words) == 0 {
+		return nil
+	}
+
+	var starts []int // source offsets of the folded runes fed to the automaton
+	var matches []Token
+	n, skipped := 0, 0
+	for i, r := range s {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		end := i + size
+		for _, c := range foldSensitive(r) {
+			if isSensitiveSeparator(c) {
+				if skipped++; skipped > w.maxSkip {
+					n = 0
+				}
+				continue
+			}
+			skipped = 0
+			starts = append(starts, i)
+
+			for n > 0 {
+				if _, ok := w.nodes[n].next[c]; ok {
+					break
+				}
+				n = w.nodes[n].fail
+			}
+			n = w.nodes[n].next[c]
+
+			for v := n; v > 0; v = w.nodes[v].output {
+				node := &w.nodes[v]
+				if node.word == "" {
+					continue
+				}
+				start := starts[len(starts)-node.length]
+				if !isWordBoundary(s, start) || !isWordBoundary(s, end) {
+					continue
+				}
+				word := w.words[node.word]
+				matches = append(matches, Token{
+					Text:  s[start:end],
+					Start: start,
+					End:   end,
+					Type:  word.category,
+					Value: word.word,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		return matches[i].End > matches[j].End
+	})
+	tokens := make([]Token, 0, len(matches))
+	for _, match := range matches {
+		if len(tokens) > 0 && match.Start < tokens[len(tokens)-1].End {
+			continue
+		}
+		tokens = append(tokens, match)
+	}
+	return tokens
+}
+
+// Fold a rune of the text or a word, the way they are matched
+func foldSensitive(r rune) string {
+	text, _ := foldRune(r, sensitiveNormalizeOptions)
+	var b strings.Builder
+	for _, c := range text {
+		b.WriteString(t2sConverter.convertChar(c))
+	}
+	return b.String()
+}
+
+func isSensitiveSeparator(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r) || unicode.Is(unicode.Cf, r)
+}
+
+// MaskTokens returns the text with every character of the tokens replaced by the mask
+func MaskTokens(s string, tokens []Token, mask string) string {
+	return ReplaceTokens(s, tokens, func(token Token) string {
+		return strings.Repeat(mask, utf8.RuneCountInString(token.Text))
+	})
+}
+
+// ReplaceTokens returns the text with the tokens replaced by the replacements, the
+// tokens are in the order of their offsets and do not overlap
+func ReplaceTokens(s string, tokens []Token, replace func(token Token) string) string {
+	var b strings.Builder
+	last := 0
+	for _, token := range tokens {
+		if token.Start < last || token.End > len(s) {
+			continue
+		}
+		b.WriteString(s[last:token.Start])
+		b.WriteString(replace(token))
+		last = token.End
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// InitSensitiveWords loads the sensitive words file in the dictionary directory
+func InitSensitiveWords() {
+	file, err := GetDictFile(SensitiveWordsFile)
+	if err != nil {
+		return
+	}
+	if err = sensitiveWords.Load(file); err != nil {
+		log.Println(err)
+	}
+}
+
+func GetSensitiveWords() *SensitiveWords {
+	return sensitiveWords
+}
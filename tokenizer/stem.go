@@ -0,0 +1,244 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import "strings"
+
+// EnglishFilter is the filter of the English words of the search mode and the
+// keyword extraction, so that Shell, shells and SHELL are the same word.
+type EnglishFilter struct {
+	LowerCase bool // words with Latin letters to lower case
+	Stem      bool // words of letters only to their Porter stems, such as shells to shell
+}
+
+// DefaultEnglishFilter lowercases and stems the English words
+var DefaultEnglishFilter = EnglishFilter{
+	LowerCase: true,
+	Stem:      true,
+}
+
+// Filter returns the filtered word, the words without Latin letters are kept as
+// they are. Only the lowercase words are stemmed, so the case is kept if LowerCase
+// is off.
+func (f *EnglishFilter) Filter(word string) string {
+	letters := false
+	for i := 0; i < len(word); i++ {
+		if isLower(rune(word[i])) || isUpper(rune(word[i])) {
+			letters = true
+			break
+		}
+	}
+	if !letters {
+		return word
+	}
+	if f.LowerCase {
+		word = strings.ToLower(word)
+	}
+	if f.Stem && isLowerWord(word) {
+		word = Stem(word)
+	}
+	return word
+}
+
+func isLowerWord(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isLower(rune(s[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// Stem returns the stem of the lowercase English word by the Porter stemming
+// algorithm, such as connect of connections. The words of two letters or less and
+// the words with characters other than a to z are kept as they are.
+func Stem(word string) string {
+	if len(word) <= 2 || !isLowerWord(word) {
+		return word
+	}
+	w := []byte(word)
+	w = stemStep1a(w)
+	w = stemStep1b(w)
+	w = stemStep1c(w)
+	w = stemReplace(w, stemStep2Suffixes, 0)
+	w = stemReplace(w, stemStep3Suffixes, 0)
+	w = stemStep4(w)
+	w = stemStep5(w)
+	return string(w)
+}
+
+var stemStep2Suffixes = [][2]string{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"},
+	{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+	{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+	{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+	{"logi", "log"},
+}
+
+var stemStep3Suffixes = [][2]string{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+var stemStep4Suffixes = []string{
+	"ement", "ment", "ance", "ence", "able", "ible", "ent", "ant", "ism", "ate",
+	"iti", "ous", "ive", "ize", "ion", "al", "er", "ic", "ou",
+}
+
+// Whether w[i] is a consonant, y is a consonant at the start or after a vowel
+func stemConsonant(w []byte, i int) bool {
+	switch w[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !stemConsonant(w, i-1)
+	}
+	return true
+}
+
+// The measure of the stem, the number of the vowel-consonant sequences in it
+func stemMeasure(w []byte) int {
+	m, i := 0, 0
+	for i < len(w) && stemConsonant(w, i) {
+		i++
+	}
+	for i < len(w) {
+		for i < len(w) && !stemConsonant(w, i) {
+			i++
+		}
+		if i >= len(w) {
+			break
+		}
+		for i < len(w) && stemConsonant(w, i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+func stemHasVowel(w []byte) bool {
+	for i := range w {
+		if !stemConsonant(w, i) {
+			return true
+		}
+	}
+	return false
+}
+
+func stemDoubleConsonant(w []byte) bool {
+	n := len(w)
+	return n >= 2 && w[n-1] == w[n-2] && stemConsonant(w, n-1)
+}
+
+// Whether the stem ends consonant-vowel-consonant and the last one is not w, x or y
+func stemCVC(w []byte) bool {
+	n := len(w)
+	if n < 3 || !stemConsonant(w, n-3) || stemConsonant(w, n-2) || !stemConsonant(w, n-1) {
+		return false
+	}
+	return w[n-1] != 'w' && w[n-1] != 'x' && w[n-1] != 'y'
+}
+
+func stemHasSuffix(w []byte, suffix string) bool {
+	return len(w) >= len(suffix) && string(w[len(w)-len(suffix):]) == suffix
+}
+
+func stemStep1a(w []byte) []byte {
+	switch {
+	case stemHasSuffix(w, "sses"), stemHasSuffix(w, "ies"):
+		return w[:len(w)-2]
+	case stemHasSuffix(w, "ss"):
+		return w
+	case stemHasSuffix(w, "s"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func stemStep1b(w []byte) []byte {
+	if stemHasSuffix(w, "eed") {
+		if stemMeasure(w[:len(w)-3]) > 0 {
+			return w[:len(w)-1]
+		}
+		return w
+	}
+
+	var stem []byte
+	switch {
+	case stemHasSuffix(w, "ed") && stemHasVowel(w[:len(w)-2]):
+		stem = w[:len(w)-2]
+	case stemHasSuffix(w, "ing") && stemHasVowel(w[:len(w)-3]):
+		stem = w[:len(w)-3]
+	default:
+		return w
+	}
+
+	switch {
+	case stemHasSuffix(stem, "at"), stemHasSuffix(stem, "bl"), stemHasSuffix(stem, "iz"):
+		return append(stem, 'e')
+	case stemDoubleConsonant(stem):
+		if c := stem[len(stem)-1]; c != 'l' && c != 's' && c != 'z' {
+			return stem[:len(stem)-1]
+		}
+	case stemMeasure(stem) == 1 && stemCVC(stem):
+		return append(stem, 'e')
+	}
+	return stem
+}
+
+func stemStep1c(w []byte) []byte {
+	if stemHasSuffix(w, "y") && stemHasVowel(w[:len(w)-1]) {
+		w[len(w)-1] = 'i'
+	}
+	return w
+}
+
+// Replace the first suffix of the list the word ends with, if the measure of the
+// stem is greater than m
+func stemReplace(w []byte, suffixes [][2]string, m int) []byte {
+	for _, v := range suffixes {
+		if !stemHasSuffix(w, v[0]) {
+			continue
+		}
+		stem := w[:len(w)-len(v[0])]
+		if stemMeasure(stem) > m {
+			return append(stem, v[1]...)
+		}
+		return w
+	}
+	return w
+}
+
+func stemStep4(w []byte) []byte {
+	for _, suffix := range stemStep4Suffixes {
+		if !stemHasSuffix(w, suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(suffix)]
+		if suffix == "ion" && !stemHasSuffix(stem, "s") && !stemHasSuffix(stem, "t") {
+			return w
+		}
+		if stemMeasure(stem) > 1 {
+			return stem
+		}
+		return w
+	}
+	return w
+}
+
+func stemStep5(w []byte) []byte {
+	if stemHasSuffix(w, "e") {
+		stem := w[:len(w)-1]
+		if m := stemMeasure(stem); m > 1 || (m == 1 && !stemCVC(stem)) {
+			w = stem
+		}
+	}
+	if stemHasSuffix(w, "ll") && stemMeasure(w) > 1 {
+		w = w[:len(w)-1]
+	}
+	return w
+}
@@ -0,0 +1,252 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SynonymMap maps the words to their synonyms by rules in the format of Solr, a
+// rule per line:
+//
+//	手机, 移动电话, cellphone   equivalent words, each one is expanded to all of them
+//	电脑, 微机 => 计算机        explicit mapping, the words on the left are replaced
+//
+// The words are matched case-insensitively, the lines starting with # are comments.
+type SynonymMap struct {
+	synonyms map[string][]string
+	mu       sync.RWMutex
+}
+
+func NewSynonymMap() *SynonymMap {
+	return &SynonymMap{
+		synonyms: make(map[string][]string),
+	}
+}
+
+var synonyms = NewSynonymMap()
+
+// AddRule adds a rule of synonyms, an error is returned if it is invalid
+func (m *SynonymMap) AddRule(rule string) error {
+	from, to, err := ParseSynonymRule(rule)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.add(from, to)
+	return nil
+}
+
+// ParseSynonymRule returns the words of a rule and the words they are expanded to,
+// the same words for the equivalent words
+func ParseSynonymRule(rule string) (from, to []string, err error) {
+	rule = strings.TrimSpace(rule)
+	if rule == "" || strings.HasPrefix(rule, "#") {
+		return nil, nil, errors.New("the synonym rule is empty")
+	}
+
+	parts := strings.Split(rule, "=>")
+	if len(parts) > 2 {
+		return nil, nil, errors.New("invalid synonym rule: " + rule)
+	}
+	from = splitSynonyms(parts[0])
+	to = from
+	if len(parts) == 2 {
+		to = splitSynonyms(parts[1])
+	}
+	if len(from) == 0 || len(to) == 0 || (len(parts) == 1 && len(from) < 2) {
+		return nil, nil, errors.New("invalid synonym rule: " + rule)
+	}
+	return from, to, nil
+}
+
+func splitSynonyms(s string) []string {
+	var words []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			words = append(words, v)
+		}
+	}
+	return words
+}
+
+func (m *SynonymMap) add(from, to []string) {
+	for _, word := range from {
+		key := strings.ToLower(word)
+		synonyms := m.synonyms[key]
+	next:
+		for _, v := range to {
+			for _, w := range synonyms {
+				if strings.EqualFold(v, w) {
+					continue next
+				}
+			}
+			synonyms = append(synonyms, v)
+		}
+		m.synonyms[key] = synonyms
+	}
+}
+
+// Whether the words are all expanded to the synonyms already
+func (m *SynonymMap) contains(from, to []string) bool {
+	for _, word := range from {
+		synonyms := m.synonyms[strings.ToLower(word)]
+	next:
+		for _, v := range to {
+			for _, w := range synonyms {
+				if strings.EqualFold(v, w) {
+					continue next
+				}
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// AddUserRule adds a rule of synonyms and appends it to the user-defined synonyms
+// file in the dictionary directory, exist is true if the rule is known already
+func (m *SynonymMap) AddUserRule(rule string) (exist bool, err error) {
+	from, to, err := ParseSynonymRule(rule)
+	if err != nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.contains(from, to) {
+		exist = true
+		return
+	}
+
+	dictStdFile, err := GetDictFile(DictStdFile)
+	if err != nil {
+		return
+	}
+
+	synonymsUserFile := filepath.Dir(dictStdFile)
+	synonymsUserFile += string(filepath.Separator) + SynonymsUserFile
+	f, err := os.OpenFile(synonymsUserFile, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return
+	}
+
+	line := ""
+	n := stat.Size()
+	if n > 0 {
+		buf := make([]byte, 1, 1)
+		_, err = f.ReadAt(buf, n-1)
+		if err != nil {
+			return
+		}
+		if buf[0] != '\n' {
+			line += "\n"
+		}
+	}
+	line += strings.TrimSpace(rule) + "\n"
+	_, err = f.Write([]byte(line))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	m.add(from, to)
+	return
+}
+
+// Lookup returns the words the word is expanded to, false if it has no synonyms.
+// The word itself is in them unless it is replaced by an explicit mapping.
+func (m *SynonymMap) Lookup(word string) ([]string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	synonyms, ok := m.synonyms[strings.ToLower(word)]
+	if !ok {
+		return nil, false
+	}
+	return append([]string(nil), synonyms...), true
+}
+
+// Len returns the number of the words which have synonyms
+func (m *SynonymMap) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.synonyms)
+}
+
+// Load loads a file of synonym rules, the invalid rules are skipped
+func (m *SynonymMap) Load(file string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	timeStart := time.Now()
+
+	f, err := os.Open(file)
+	if err != nil {
+		log.Println(err)
+		return errors.New("unable to load the synonym file:" + filepath.Base(file))
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	itemCount := 0
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			break
+		}
+
+		from, to, e := ParseSynonymRule(line)
+		if e == nil {
+			itemCount++
+			m.add(from, to)
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	log.Printf("%v rules are loaded in synonym file "+filepath.Base(file)+", and take %v\n",
+		itemCount, time.Now().Sub(timeStart))
+	return nil
+}
+
+// InitSynonyms loads the standard and the user-defined synonyms files in the
+// dictionary directory
+func InitSynonyms() {
+	for _, v := range []string{SynonymsStdFile, SynonymsUserFile} {
+		file, err := GetDictFile(v)
+		if err != nil {
+			continue
+		}
+		if err = synonyms.Load(file); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func GetSynonyms() *SynonymMap {
+	return synonyms
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"sort"
+	"strings"
+)
+
+// TextMap is a text transformed from a source text, such as the visible text of a
+// markup or a normalized text, with the map from each byte of the text back to the
+// bytes of the source it comes from.
+type TextMap struct {
+	Text   string // the transformed text
+	Source string // the source text
+
+	b         strings.Builder
+	starts    []int  // starts[i] is the source offset of the source of Text[i]
+	ends      []int  // ends[i] is the source end offset of the source of Text[i]
+	separator []bool // whether Text[i] is a space put in place of some source, such as a tag
+}
+
+func newTextMap(s string) *TextMap {
+	return &TextMap{
+		Source:    s,
+		starts:    make([]int, 0, len(s)),
+		ends:      make([]int, 0, len(s)),
+		separator: make([]bool, 0, len(s)),
+	}
+}
+
+// Finish the text
+func (m *TextMap) done() *TextMap {
+	m.Text = m.b.String()
+	m.b.Reset()
+	return m
+}
+
+// Offsets returns the source range of the text range [start, end)
+func (m *TextMap) Offsets(start, end int) (int, int) {
+	if start >= end || start < 0 || end > len(m.starts) {
+		if start >= 0 && start < len(m.starts) {
+			return m.starts[start], m.starts[start]
+		}
+		return len(m.Source), len(m.Source)
+	}
+	return m.starts[start], m.ends[end-1]
+}
+
+// TextOffset returns the offset in the text of the source offset, the text
+// from the offset on comes from the source from the source offset on.
+func (m *TextMap) TextOffset(offset int) int {
+	return sort.Search(len(m.starts), func(i int) bool {
+		return m.starts[i] >= offset
+	})
+}
+
+// MapTokens maps the tokens cut from the text to the source. The Start and End of
+// the returned tokens are offsets in the source, the texts are kept as they are in
+// the text. The spaces which are only put in place of some source are dropped.
+func (m *TextMap) MapTokens(tokens []Token) []Token {
+	mapped := make([]Token, 0, len(tokens))
+	for _, token := range tokens {
+		if m.isSeparator(token.Start, token.End) {
+			continue
+		}
+		token.Start, token.End = m.Offsets(token.Start, token.End)
+		mapped = append(mapped, token)
+	}
+	return mapped
+}
+
+// MapSpans maps the spans of the source to the text
+func (m *TextMap) MapSpans(spans []Span) []Span {
+	mapped := make([]Span, 0, len(spans))
+	for _, span := range spans {
+		span.Start, span.End = m.TextOffset(span.Start), m.TextOffset(span.End)
+		mapped = append(mapped, span)
+	}
+	return mapped
+}
+
+func (m *TextMap) isSeparator(start, end int) bool {
+	for i := start; i < end && i < len(m.separator); i++ {
+		if !m.separator[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Append the text which comes from the source range [start, end)
+func (m *TextMap) write(text string, start, end int) {
+	m.b.WriteString(text)
+	for i := 0; i < len(text); i++ {
+		m.starts = append(m.starts, start)
+		m.ends = append(m.ends, end)
+		m.separator = append(m.separator, false)
+	}
+}
+
+// Append a space in place of the source range [start, end), unless the text already
+// ends with one
+func (m *TextMap) writeSeparator(start, end int) {
+	if n := len(m.separator); n > 0 && m.separator[n-1] {
+		return
+	}
+	m.b.WriteByte(' ')
+	m.starts = append(m.starts, start)
+	m.ends = append(m.ends, end)
+	m.separator = append(m.separator, true)
+}
+
+// Append the source text as it is, its offset in the source is base
+func (m *TextMap) copyText(s string, base int) {
+	m.b.WriteString(s)
+	for i := 0; i < len(s); i++ {
+		m.starts = append(m.starts, base+i)
+		m.ends = append(m.ends, base+i+1)
+		m.separator = append(m.separator, false)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 Ze-Bin Wang.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenizer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Token is a word with its byte offsets in the text, text[Start:End] is the word as
+// it is in the text. Text is the word after the normalization and the English filter,
+// if they are on, so it may differ from text[Start:End], such as shell of ＳＨＥＬＬ.
+type Token struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Type  string `json:"type,omitempty"`  // type label of a protected span, empty for a word
+	Value string `json:"value,omitempty"` // normalized value of a protected span
+}
+
+// Append the emitted words as tokens, the rune offsets in the sentence are turned
+// into byte offsets from base.
+func appendToken(s string, base int, tokens *[]Token) wordEmitter {
+	offsets := make([]int, 0, len(s)+1)
+	for i := range s {
+		offsets = append(offsets, base+i)
+	}
+	offsets = append(offsets, base+len(s))
+
+	return func(word string, start, end int, _, _ string) {
+		*tokens = append(*tokens, Token{
+			Text:  word,
+			Start: offsets[start],
+			End:   offsets[end],
+		})
+	}
+}
+
+// AppendWordTokens appends the words cut from s as tokens. The words must follow
+// each other in s, a word not found in s gets an empty span at the current position.
+func AppendWordTokens(s string, base int, words []string, tokens *[]Token) {
+	pos := 0
+	for _, word := range words {
+		start := pos
+		if !strings.HasPrefix(s[pos:], word) {
+			if i := strings.Index(s[pos:], word); i >= 0 {
+				start = pos + i
+			} else {
+				*tokens = append(*tokens, Token{Text: word, Start: base + pos, End: base + pos})
+				continue
+			}
+		}
+		pos = start + len(word)
+		*tokens = append(*tokens, Token{Text: word, Start: base + start, End: base + pos})
+	}
+}
+
+// TokenTexts returns the texts of the tokens
+func TokenTexts(tokens []Token) []string {
+	words := make([]string, len(tokens))
+	for i, v := range tokens {
+		words[i] = v.Text
+	}
+	return words
+}
+
+// CutFullT cuts the text the same way as CutFullW, the tokens have byte offsets from base.
+func (sg *Segmenter) CutFullT(s string, base int, tokens *[]Token) {
+	sg.cutFull(NewSentence(s), appendToken(s, base, tokens))
+}
+
+// CutAccurateT cuts the text the same way as CutAccurateW, the tokens have byte offsets from base.
+func (sg *Segmenter) CutAccurateT(s string, base int, tokens *[]Token) {
+	sentence := NewSentence(s)
+	sg.cutAccurate(sentence, sg.calcRoute(sentence), appendToken(s, base, tokens))
+}
+
+// CutNoHMMT cuts the text the same way as CutNoHMMW, the tokens have byte offsets from base.
+func (sg *Segmenter) CutNoHMMT(s string, base int, tokens *[]Token) {
+	sentence := NewSentence(s)
+	sg.cutNoHMM(sentence, sg.calcRoute(sentence), appendToken(s, base, tokens))
+}
+
+// CutFMMT cuts the text the same way as CutFMMW, the tokens have byte offsets from base.
+func (sg *Segmenter) CutFMMT(s string, base int, tokens *[]Token) {
+	words := make([]string, 0, DefaultWordsLen)
+	sg.CutFMMW(s, &words)
+	AppendWordTokens(s, base, words, tokens)
+}
+
+// CutBMMT cuts the text the same way as CutBMMW, the tokens have byte offsets from base.
+func (sg *Segmenter) CutBMMT(s string, base int, tokens *[]Token) {
+	words := make([]string, 0, DefaultWordsLen)
+	sg.CutBMMW(s, &words)
+	AppendWordTokens(s, base, words, tokens)
+}
+
+// CutBiMMT cuts the text the same way as CutBiMMW, the tokens have byte offsets from base.
+func (sg *Segmenter) CutBiMMT(s string, base int, tokens *[]Token) {
+	words := make([]string, 0, DefaultWordsLen)
+	sg.CutBiMMW(s, &words)
+	AppendWordTokens(s, base, words, tokens)
+}
+
+// CutSymbolT cuts the symbols the same way as CutSymbolW with the delimiters of the
+// segmenter, the tokens have byte offsets from base.
+func (sg *Segmenter) CutSymbolT(s string, base int, tokens *[]Token) {
+	words := make([]string, 0, DefaultWordsLen)
+	sg.GetCharClass().CutSymbolW(s, &words)
+	AppendWordTokens(s, base, words, tokens)
+}
+
+// RuneOffsets turns the byte offsets of the tokens cut from s into rune offsets, the
+// tokens are changed in place.
+func RuneOffsets(s string, tokens []Token) []Token {
+	runes := make([]int, len(s)+1)
+	n := -1
+	for i := 0; i < len(s); i++ {
+		if utf8.RuneStart(s[i]) {
+			n++
+		}
+		runes[i] = n
+	}
+	runes[len(s)] = n + 1
+	for i := range tokens {
+		tokens[i].Start, tokens[i].End = runes[tokens[i].Start], runes[tokens[i].End]
+	}
+	return tokens
+}